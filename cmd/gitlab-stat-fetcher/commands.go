@@ -0,0 +1,395 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/urfave/cli/v2"
+
+	gl "github.com/AlexeyBelezeko/GitlabStatFetcher/pkg/gitlab"
+	"github.com/AlexeyBelezeko/GitlabStatFetcher/pkg/sink"
+)
+
+// commonFetchFlags are the flags shared by "fetch" and "resume": what to
+// fetch, how hard to hammer the GitLab instance, and where to put the
+// output. Each is also readable from the env var it replaces, so existing
+// .env-based setups keep working.
+func commonFetchFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{Name: "config", Usage: "Config file written by \"config init\"; its values are used wherever a flag below isn't passed"},
+		&cli.StringFlag{Name: "gitlab-url", EnvVars: []string{"GITLAB_URL"}, Usage: "Base URL of the GitLab instance"},
+		&cli.StringFlag{Name: "gitlab-token", EnvVars: []string{"GITLAB_TOKEN"}, Usage: "GitLab personal access token"},
+		&cli.StringFlag{Name: "data-dir", EnvVars: []string{"DATA_FOLDER"}, Usage: "Directory for output files and fetch state"},
+		&cli.StringFlag{Name: "since", EnvVars: []string{"SINCE_DATE"}, Usage: "Only fetch commits/MRs since this date (YYYY-MM-DD), default 2 years ago"},
+		&cli.IntFlag{Name: "workers", EnvVars: []string{"GITLAB_WORKERS"}, Value: 1, Usage: "Number of concurrent fetch workers"},
+		&cli.BoolFlag{Name: "skip-discussions", Usage: "Skip fetching merge request discussions"},
+		&cli.BoolFlag{Name: "skip-pipelines", Usage: "Skip fetching pipelines"},
+		&cli.BoolFlag{Name: "skip-issues", Usage: "Skip fetching issues"},
+		&cli.BoolFlag{Name: "skip-approvals", Usage: "Skip fetching merge request approvals"},
+		&cli.BoolFlag{Name: "skip-mr-commits", Usage: "Skip fetching the commits making up each merge request"},
+		&cli.StringFlag{Name: "format", EnvVars: []string{"OUTPUT_FORMAT"}, Value: "csv", Usage: "Output format: csv, jsonl, parquet, sql"},
+		&cli.StringFlag{Name: "sql-dsn", EnvVars: []string{"SQL_DSN"}, Usage: "DSN for --format sql, e.g. sqlite://path or postgres://..."},
+		&cli.StringFlag{Name: "include-glob", Usage: "Only fetch projects whose path matches this glob (matched against the full namespace path)"},
+		&cli.StringFlag{Name: "exclude-glob", Usage: "Skip projects whose path matches this glob"},
+		&cli.DurationFlag{Name: "cache-ttl", EnvVars: []string{"HTTP_CACHE_TTL"}, Value: time.Hour, Usage: "How long to trust a cached HTTP response before revalidating with GitLab"},
+		&cli.Float64Flag{Name: "rps", EnvVars: []string{"GITLAB_RPS"}, Value: 5, Usage: "Max requests/sec against the GitLab API"},
+		&cli.IntFlag{Name: "burst", EnvVars: []string{"GITLAB_BURST"}, Value: 5, Usage: "Token-bucket burst size for --rps"},
+		&cli.BoolFlag{Name: "silent", Usage: "Suppress banners and progress bars"},
+		&cli.BoolFlag{Name: "no-progress", Usage: "Disable progress bars (structured log events are still emitted)"},
+	}
+}
+
+func fetchCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "fetch",
+		Usage:     "Fetch commits, merge requests and discussions for one or more project/group URLs",
+		ArgsUsage: "<url> [url...]",
+		Flags:     commonFetchFlags(),
+		Action:    runFetch,
+	}
+}
+
+func resumeCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "resume",
+		Usage:     "Resume a previous fetch, skipping projects already recorded in .fetched_projects",
+		ArgsUsage: "<url> [url...]",
+		Flags:     commonFetchFlags(),
+		Action:    runFetch,
+	}
+}
+
+func runFetch(c *cli.Context) error {
+	if c.Args().Len() == 0 {
+		return fmt.Errorf("at least one project or group URL is required")
+	}
+
+	cfg, err := loadFetchConfig(c)
+	if err != nil {
+		return err
+	}
+
+	gitlabURL := stringOrConfig(c, "gitlab-url", cfg.GitlabURL)
+	gitlabToken := stringOrConfig(c, "gitlab-token", cfg.GitlabToken)
+	dataDir := stringOrConfig(c, "data-dir", cfg.DataDir)
+	if gitlabURL == "" || gitlabToken == "" || dataDir == "" {
+		return fmt.Errorf("--gitlab-url, --gitlab-token and --data-dir are required (via flag, env var or --config)")
+	}
+
+	sinceDate, err := parseSinceDate(stringOrConfig(c, "since", cfg.Since))
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Fetching data since: %s\n", sinceDate.Format("2006-01-02"))
+
+	if err := os.MkdirAll(dataDir, 0755); err != nil {
+		return fmt.Errorf("creating data directory: %v", err)
+	}
+
+	client, err := gl.NewClient(gitlabToken, gitlabURL, gl.ClientOptions{
+		CacheDir: filepath.Join(dataDir, ".httpcache"),
+		CacheTTL: c.Duration("cache-ttl"),
+		RPS:      c.Float64("rps"),
+		Burst:    c.Int("burst"),
+	})
+	if err != nil {
+		return err
+	}
+
+	includeGlob := stringOrConfig(c, "include-glob", cfg.IncludeGlob)
+	excludeGlob := stringOrConfig(c, "exclude-glob", cfg.ExcludeGlob)
+
+	source := gl.NewGitLabSource(client, gitlabURL)
+	projects, err := source.ResolveProjects(c.Args().Slice(), includeGlob, excludeGlob)
+	if err != nil {
+		return err
+	}
+
+	s, err := sink.New(stringOrConfig(c, "format", cfg.Format), dataDir, stringOrConfig(c, "sql-dsn", cfg.SQLDSN))
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	fetchedProjects := gl.LoadFetchedProjects(dataDir)
+	fmt.Printf("Found %d already fetched projects\n", len(fetchedProjects))
+
+	workers := intOrConfig(c, "workers", cfg.Workers)
+	skips := fetchSkips{
+		Discussions: boolOrConfig(c, "skip-discussions", cfg.SkipDiscussions),
+		Pipelines:   boolOrConfig(c, "skip-pipelines", cfg.SkipPipelines),
+		Issues:      boolOrConfig(c, "skip-issues", cfg.SkipIssues),
+		Approvals:   boolOrConfig(c, "skip-approvals", cfg.SkipApprovals),
+		MRCommits:   boolOrConfig(c, "skip-mr-commits", cfg.SkipMRCommits),
+	}
+	silent := c.Bool("silent")
+	progress := NewProgress(silent, c.Bool("no-progress"))
+	fetcher := gl.NewFetcher(client, workers)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	for _, project := range projects {
+		if ctx.Err() != nil {
+			break
+		}
+		if fetchedProjects[project.ID] {
+			continue
+		}
+
+		if !silent {
+			fmt.Println("\n" + strings.Repeat("=", 80))
+			fmt.Printf("Fetching: %s (ID: %d)\n", project.PathWithNamespace, project.ID)
+			fmt.Println(strings.Repeat("=", 80))
+		}
+
+		checkpoint := gl.LoadCheckpoint(dataDir, project.ID)
+		counts := fetchAll(ctx, fetcher, project.ID, s, checkpoint, sinceDate, skips, progress)
+		if err := s.Flush(); err != nil && !silent {
+			// A buffering sink (parquet) writes one project's rows here; a
+			// failure just means this project's output wasn't durably
+			// written, so say so but keep going rather than aborting the run.
+			fmt.Printf("⚠️  flushing %s: %v\n", project.PathWithNamespace, err)
+		}
+		if ctx.Err() != nil {
+			// Interrupted mid-project: the checkpoint already records how
+			// far each phase got, so leave the project off
+			// .fetched_projects and let the next "resume" pick up from
+			// last_page+1 instead of re-downloading it from scratch.
+			break
+		}
+		if counts.Incomplete {
+			// A phase errored rather than finishing (e.g. a transient API
+			// failure): the checkpoint still records real progress, so
+			// leave it and .fetched_projects alone and move on to the next
+			// project instead of pretending this one is done.
+			if !silent {
+				fmt.Printf("⚠️  %s had fetch errors — checkpoint kept for \"resume\"\n", project.PathWithNamespace)
+			}
+			continue
+		}
+		gl.ClearCheckpoint(dataDir, project.ID)
+		gl.MarkProjectFetched(dataDir, project.ID, project.PathWithNamespace)
+	}
+
+	if !silent {
+		fmt.Println("\n" + strings.Repeat("=", 80))
+		if ctx.Err() != nil {
+			fmt.Println("FETCH INTERRUPTED — run \"resume\" to continue where it left off")
+		} else {
+			fmt.Println("ALL PROJECTS COMPLETED!")
+		}
+		fmt.Println(strings.Repeat("=", 80))
+	}
+	return nil
+}
+
+func statusCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "status",
+		Usage: "Show how many projects have already been fetched into a data directory",
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "data-dir", EnvVars: []string{"DATA_FOLDER"}, Required: true, Usage: "Directory for output files and fetch state"},
+		},
+		Action: runStatus,
+	}
+}
+
+func runStatus(c *cli.Context) error {
+	dataDir := c.String("data-dir")
+	fetched := gl.LoadFetchedProjects(dataDir)
+	fmt.Printf("%d project(s) fetched into %s\n", len(fetched), dataDir)
+	return nil
+}
+
+func listProjectsCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "list-projects",
+		Usage:     "List the projects a set of project/group URLs resolve to, without fetching data",
+		ArgsUsage: "<url> [url...]",
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "gitlab-url", EnvVars: []string{"GITLAB_URL"}, Required: true},
+			&cli.StringFlag{Name: "gitlab-token", EnvVars: []string{"GITLAB_TOKEN"}, Required: true},
+			&cli.StringFlag{Name: "include-glob", Usage: "Only list projects whose path matches this glob"},
+			&cli.StringFlag{Name: "exclude-glob", Usage: "Skip projects whose path matches this glob"},
+		},
+		Action: runListProjects,
+	}
+}
+
+func runListProjects(c *cli.Context) error {
+	if c.Args().Len() == 0 {
+		return fmt.Errorf("at least one project or group URL is required")
+	}
+
+	client, err := gl.NewClient(c.String("gitlab-token"), c.String("gitlab-url"), gl.ClientOptions{})
+	if err != nil {
+		return err
+	}
+
+	source := gl.NewGitLabSource(client, c.String("gitlab-url"))
+	projects, err := source.ResolveProjects(c.Args().Slice(), c.String("include-glob"), c.String("exclude-glob"))
+	if err != nil {
+		return err
+	}
+
+	for _, project := range projects {
+		fmt.Printf("%d\t%s\n", project.ID, project.PathWithNamespace)
+	}
+	fmt.Printf("%d project(s)\n", len(projects))
+	return nil
+}
+
+func exportCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "export",
+		Usage: "Convert a data directory's CSV output to another sink format",
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "data-dir", EnvVars: []string{"DATA_FOLDER"}, Required: true, Usage: "Directory containing commits.csv/merge_requests.csv/notes.csv"},
+			&cli.StringFlag{Name: "format", Required: true, Usage: "Output format: jsonl, parquet, sql"},
+			&cli.StringFlag{Name: "sql-dsn", EnvVars: []string{"SQL_DSN"}, Usage: "DSN for --format sql"},
+		},
+		Action: runExport,
+	}
+}
+
+func runExport(c *cli.Context) error {
+	dataDir := c.String("data-dir")
+	format := c.String("format")
+	if format == "" || format == "csv" {
+		return fmt.Errorf("--format must be jsonl, parquet or sql (existing output is already csv)")
+	}
+
+	export, err := sink.ReadCSV(dataDir)
+	if err != nil {
+		return err
+	}
+
+	s, err := sink.New(format, dataDir, c.String("sql-dsn"))
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	if err := s.WriteCommits(export.Commits); err != nil {
+		return fmt.Errorf("writing commits: %v", err)
+	}
+	if err := s.WriteMRs(export.MRs); err != nil {
+		return fmt.Errorf("writing MRs: %v", err)
+	}
+	if err := s.WriteNotes(export.Notes); err != nil {
+		return fmt.Errorf("writing notes: %v", err)
+	}
+	if err := s.WritePipelines(export.Pipelines); err != nil {
+		return fmt.Errorf("writing pipelines: %v", err)
+	}
+	if err := s.WriteIssues(export.Issues); err != nil {
+		return fmt.Errorf("writing issues: %v", err)
+	}
+	if err := s.WriteMRApprovals(export.MRApprovals); err != nil {
+		return fmt.Errorf("writing MR approvals: %v", err)
+	}
+	if err := s.WriteMRCommits(export.MRCommits); err != nil {
+		return fmt.Errorf("writing MR commits: %v", err)
+	}
+
+	fmt.Printf("✓ Exported %d commits, %d merge requests, %d notes, %d pipelines, %d issues, %d MR approvals, %d MR commits to --format %s\n",
+		len(export.Commits), len(export.MRs), len(export.Notes), len(export.Pipelines),
+		len(export.Issues), len(export.MRApprovals), len(export.MRCommits), format)
+	return nil
+}
+
+func configCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "config",
+		Usage:     "Write or show a config file so fetch flags don't need to be retyped every run",
+		ArgsUsage: "<config-file>",
+		Subcommands: []*cli.Command{
+			{
+				Name:      "init",
+				Usage:     "Write a new config file from the given flags",
+				ArgsUsage: "<config-file>",
+				Flags:     commonFetchFlags(),
+				Action:    runConfigInit,
+			},
+			{
+				Name:      "show",
+				Usage:     "Print a config file's contents",
+				ArgsUsage: "<config-file>",
+				Action:    runConfigShow,
+			},
+		},
+	}
+}
+
+func runConfigInit(c *cli.Context) error {
+	if c.Args().Len() != 1 {
+		return fmt.Errorf("usage: gitlab-stat-fetcher config init <config-file>")
+	}
+
+	cfg := &Config{
+		GitlabURL:       c.String("gitlab-url"),
+		GitlabToken:     c.String("gitlab-token"),
+		DataDir:         c.String("data-dir"),
+		Workers:         c.Int("workers"),
+		Since:           c.String("since"),
+		Format:          c.String("format"),
+		SQLDSN:          c.String("sql-dsn"),
+		SkipDiscussions: c.Bool("skip-discussions"),
+		SkipPipelines:   c.Bool("skip-pipelines"),
+		SkipIssues:      c.Bool("skip-issues"),
+		SkipApprovals:   c.Bool("skip-approvals"),
+		SkipMRCommits:   c.Bool("skip-mr-commits"),
+		IncludeGlob:     c.String("include-glob"),
+		ExcludeGlob:     c.String("exclude-glob"),
+	}
+
+	configFile := c.Args().First()
+	if err := SaveConfig(configFile, cfg); err != nil {
+		return fmt.Errorf("writing config: %v", err)
+	}
+	fmt.Printf("✓ Wrote config: %s\n", configFile)
+	return nil
+}
+
+func runConfigShow(c *cli.Context) error {
+	if c.Args().Len() != 1 {
+		return fmt.Errorf("usage: gitlab-stat-fetcher config show <config-file>")
+	}
+
+	cfg, err := LoadConfig(c.Args().First())
+	if err != nil {
+		return fmt.Errorf("reading config: %v", err)
+	}
+
+	fmt.Printf("gitlab_url: %s\n", cfg.GitlabURL)
+	fmt.Printf("data_dir: %s\n", cfg.DataDir)
+	fmt.Printf("workers: %d\n", cfg.Workers)
+	fmt.Printf("since: %s\n", cfg.Since)
+	fmt.Printf("format: %s\n", cfg.Format)
+	fmt.Printf("skip_discussions: %t\n", cfg.SkipDiscussions)
+	fmt.Printf("skip_pipelines: %t\n", cfg.SkipPipelines)
+	fmt.Printf("skip_issues: %t\n", cfg.SkipIssues)
+	fmt.Printf("skip_approvals: %t\n", cfg.SkipApprovals)
+	fmt.Printf("skip_mr_commits: %t\n", cfg.SkipMRCommits)
+	fmt.Printf("include_glob: %s\n", cfg.IncludeGlob)
+	fmt.Printf("exclude_glob: %s\n", cfg.ExcludeGlob)
+	return nil
+}
+
+func parseSinceDate(value string) (*time.Time, error) {
+	if value == "" {
+		return gl.Ptr(time.Now().AddDate(-2, 0, 0)), nil
+	}
+	parsed, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --since date %q (use YYYY-MM-DD): %v", value, err)
+	}
+	return gl.Ptr(parsed), nil
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// Config mirrors the fetch flags so users can persist a profile (written by
+// the "config" command) instead of juggling .env plus a long flag list on
+// every invocation. The format is picked from the file extension: ".yaml"
+// or ".yml" for YAML, anything else for JSON.
+type Config struct {
+	GitlabURL       string `json:"gitlab_url" yaml:"gitlab_url"`
+	GitlabToken     string `json:"gitlab_token,omitempty" yaml:"gitlab_token,omitempty"`
+	DataDir         string `json:"data_dir" yaml:"data_dir"`
+	Workers         int    `json:"workers" yaml:"workers"`
+	Since           string `json:"since,omitempty" yaml:"since,omitempty"`
+	Format          string `json:"format" yaml:"format"`
+	SQLDSN          string `json:"sql_dsn,omitempty" yaml:"sql_dsn,omitempty"`
+	SkipDiscussions bool   `json:"skip_discussions" yaml:"skip_discussions"`
+	SkipPipelines   bool   `json:"skip_pipelines" yaml:"skip_pipelines"`
+	SkipIssues      bool   `json:"skip_issues" yaml:"skip_issues"`
+	SkipApprovals   bool   `json:"skip_approvals" yaml:"skip_approvals"`
+	SkipMRCommits   bool   `json:"skip_mr_commits" yaml:"skip_mr_commits"`
+	IncludeGlob     string `json:"include_glob,omitempty" yaml:"include_glob,omitempty"`
+	ExcludeGlob     string `json:"exclude_glob,omitempty" yaml:"exclude_glob,omitempty"`
+}
+
+// LoadConfig reads a Config previously written by SaveConfig.
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &Config{}
+	if isYAMLPath(path) {
+		err = yaml.Unmarshal(data, cfg)
+	} else {
+		err = json.Unmarshal(data, cfg)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parsing config %s: %v", path, err)
+	}
+	return cfg, nil
+}
+
+// SaveConfig writes cfg to path, creating or overwriting it.
+func SaveConfig(path string, cfg *Config) error {
+	var (
+		data []byte
+		err  error
+	)
+	if isYAMLPath(path) {
+		data, err = yaml.Marshal(cfg)
+	} else {
+		data, err = json.MarshalIndent(cfg, "", "  ")
+	}
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+func isYAMLPath(path string) bool {
+	return strings.HasSuffix(path, ".yaml") || strings.HasSuffix(path, ".yml")
+}
+
+// loadFetchConfig loads the config named by the --config flag, or returns an
+// empty Config if the flag wasn't passed, so runFetch can treat "no config"
+// and "config with nothing set" identically.
+func loadFetchConfig(c *cli.Context) (*Config, error) {
+	path := c.String("config")
+	if path == "" {
+		return &Config{}, nil
+	}
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		return nil, fmt.Errorf("loading --config: %v", err)
+	}
+	return cfg, nil
+}
+
+// stringOrConfig returns the flag's value if the user passed it explicitly,
+// otherwise cfgVal if set, otherwise the flag's own default.
+func stringOrConfig(c *cli.Context, flag, cfgVal string) string {
+	if c.IsSet(flag) || cfgVal == "" {
+		return c.String(flag)
+	}
+	return cfgVal
+}
+
+// intOrConfig is stringOrConfig for int-valued flags.
+func intOrConfig(c *cli.Context, flag string, cfgVal int) int {
+	if c.IsSet(flag) || cfgVal == 0 {
+		return c.Int(flag)
+	}
+	return cfgVal
+}
+
+// boolOrConfig is stringOrConfig for bool-valued flags; an explicit flag
+// always wins since a config value of false is indistinguishable from unset.
+func boolOrConfig(c *cli.Context, flag string, cfgVal bool) bool {
+	if c.IsSet(flag) {
+		return c.Bool(flag)
+	}
+	return cfgVal
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"log"
+	"strings"
+	"time"
+
+	gl "github.com/AlexeyBelezeko/GitlabStatFetcher/pkg/gitlab"
+	"github.com/AlexeyBelezeko/GitlabStatFetcher/pkg/sink"
+)
+
+// fetchSkips selects which optional entities fetchAll collects, mirroring
+// the --skip-* flags in commonFetchFlags.
+type fetchSkips struct {
+	Discussions bool
+	Pipelines   bool
+	Issues      bool
+	Approvals   bool
+	MRCommits   bool
+}
+
+// fetchCounts tallies what fetchAll wrote, for the per-project summary.
+// Items are written to the sink page by page as they arrive rather than
+// buffered, so this is the only record of "how much" once the run is done.
+type fetchCounts struct {
+	Commits, MRs, Notes, Pipelines, Issues, Approvals, MRCommits int
+	// Incomplete is true if ctx was canceled or any phase errored before
+	// finishing, so the caller knows not to clear the checkpoint or mark
+	// the project fetched even though fetchAll itself always returns.
+	Incomplete bool
+}
+
+// fetchAll fetches all per-project stats, writing each page to s and
+// checkpoint as soon as it's fetched so ctx being canceled mid-project
+// (Ctrl-C) loses at most the one page in flight. It returns early, without
+// touching phases it hasn't reached yet, once ctx is done.
+func fetchAll(ctx context.Context, fetcher *gl.Fetcher, projectID int64, s sink.Sink, checkpoint *gl.Checkpoint, sinceDate *time.Time, skips fetchSkips, progress *Progress) fetchCounts {
+	startTime := time.Now()
+	var counts fetchCounts
+	var err error
+
+	counts.Commits, err = streamPages(checkpoint, gl.PhaseCommits,
+		fetcher.FetchCommits(ctx, projectID, gl.FetchOptions{Since: sinceDate, Progress: progress, StartPage: checkpoint.LastPage(gl.PhaseCommits) + 1}),
+		s.WriteCommits, "commits")
+	counts.Incomplete = counts.Incomplete || err != nil
+
+	mrIIDs := checkpoint.SavedMRIIDs()
+	if ctx.Err() == nil {
+		counts.MRs, err = streamPages(checkpoint, gl.PhaseMRs,
+			fetcher.FetchMRs(ctx, projectID, gl.FetchOptions{Since: sinceDate, Progress: progress, StartPage: checkpoint.LastPage(gl.PhaseMRs) + 1}),
+			func(mrs []*gl.BasicMergeRequest) error {
+				for _, mr := range mrs {
+					mrIIDs = append(mrIIDs, mr.IID)
+				}
+				checkpoint.SetMRIIDs(mrIIDs)
+				return s.WriteMRs(mrs)
+			}, "merge requests")
+		counts.Incomplete = counts.Incomplete || err != nil
+	}
+
+	if ctx.Err() == nil && !skips.Discussions {
+		counts.Notes, err = streamPages(checkpoint, gl.PhaseDiscussions,
+			fetcher.FetchDiscussions(ctx, projectID, mrIIDs, checkpoint.LastPage(gl.PhaseDiscussions)+1, progress),
+			skipEmpty(s.WriteNotes), "discussions")
+		counts.Incomplete = counts.Incomplete || err != nil
+	} else if skips.Discussions {
+		log.Println("Skipping discussions (--skip-discussions flag set)")
+	}
+
+	if ctx.Err() == nil && !skips.Pipelines {
+		counts.Pipelines, err = streamPages(checkpoint, gl.PhasePipelines,
+			fetcher.FetchPipelines(ctx, projectID, gl.FetchOptions{Since: sinceDate, Progress: progress, StartPage: checkpoint.LastPage(gl.PhasePipelines) + 1}),
+			skipEmpty(s.WritePipelines), "pipelines")
+		counts.Incomplete = counts.Incomplete || err != nil
+	} else if skips.Pipelines {
+		log.Println("Skipping pipelines (--skip-pipelines flag set)")
+	}
+
+	if ctx.Err() == nil && !skips.Issues {
+		counts.Issues, err = streamPages(checkpoint, gl.PhaseIssues,
+			fetcher.FetchIssues(ctx, projectID, gl.FetchOptions{Since: sinceDate, Progress: progress, StartPage: checkpoint.LastPage(gl.PhaseIssues) + 1}),
+			skipEmpty(s.WriteIssues), "issues")
+		counts.Incomplete = counts.Incomplete || err != nil
+	} else if skips.Issues {
+		log.Println("Skipping issues (--skip-issues flag set)")
+	}
+
+	if ctx.Err() == nil && !skips.Approvals {
+		counts.Approvals, err = streamPages(checkpoint, gl.PhaseApprovals,
+			fetcher.FetchMRApprovals(ctx, projectID, mrIIDs, checkpoint.LastPage(gl.PhaseApprovals)+1, progress),
+			skipEmpty(s.WriteMRApprovals), "merge request approvals")
+		counts.Incomplete = counts.Incomplete || err != nil
+	} else if skips.Approvals {
+		log.Println("Skipping merge request approvals (--skip-approvals flag set)")
+	}
+
+	if ctx.Err() == nil && !skips.MRCommits {
+		counts.MRCommits, err = streamPages(checkpoint, gl.PhaseMRCommits,
+			fetcher.FetchMRCommits(ctx, projectID, mrIIDs, checkpoint.LastPage(gl.PhaseMRCommits)+1, progress),
+			skipEmpty(s.WriteMRCommits), "merge request commits")
+		counts.Incomplete = counts.Incomplete || err != nil
+	} else if skips.MRCommits {
+		log.Println("Skipping merge request commits (--skip-mr-commits flag set)")
+	}
+	counts.Incomplete = counts.Incomplete || ctx.Err() != nil
+
+	if progress.Silent() {
+		return counts
+	}
+
+	if ctx.Err() != nil {
+		fmt.Println("⚠️  Interrupted — partial output saved, checkpoint recorded for resume")
+		return counts
+	}
+
+	if counts.Incomplete {
+		fmt.Println("⚠️  Finished with errors — partial output saved, checkpoint recorded for resume")
+		return counts
+	}
+
+	totalTime := time.Since(startTime)
+	fmt.Println("\n" + strings.Repeat("=", 60))
+	fmt.Println("FETCH COMPLETE!")
+	fmt.Println(strings.Repeat("=", 60))
+	fmt.Printf("Total time: %v\n", totalTime)
+	fmt.Printf("Commits: %d\n", counts.Commits)
+	fmt.Printf("Merge Requests: %d\n", counts.MRs)
+	fmt.Printf("Notes: %d\n", counts.Notes)
+	fmt.Printf("Pipelines: %d\n", counts.Pipelines)
+	fmt.Printf("Issues: %d\n", counts.Issues)
+	fmt.Printf("MR Approvals: %d\n", counts.Approvals)
+	fmt.Printf("MR Commits: %d\n", counts.MRCommits)
+	fmt.Println(strings.Repeat("=", 60))
+
+	if totalTime > 5*time.Minute {
+		fmt.Println("⚠️  Exceeded 5-minute target")
+	} else {
+		fmt.Println("✓ Completed within 5-minute target")
+	}
+	return counts
+}
+
+// streamPages drains pages, writing each one to the sink via write as soon
+// as it arrives and, once a contiguous run of pages has been durably
+// written, advancing checkpoint's phase watermark so a resumed run knows
+// it can skip straight to last_page+1. It returns the total item count
+// written, and the error that stopped it short, if any, so the caller
+// knows this phase didn't finish and shouldn't be treated as complete.
+func streamPages[T any](checkpoint *gl.Checkpoint, phase string, pages iter.Seq2[gl.Page[T], error], write func([]T) error, label string) (int, error) {
+	tracker := gl.NewPageTracker(checkpoint.LastPage(phase) + 1)
+	var total int
+
+	for page, err := range pages {
+		if err != nil {
+			log.Printf("Error fetching %s: %v", label, err)
+			return total, err
+		}
+		if err := write(page.Items); err != nil {
+			log.Printf("Error writing %s: %v", label, err)
+			return total, err
+		}
+		total += len(page.Items)
+		if watermark, advanced := tracker.Mark(page.Number); advanced {
+			checkpoint.Advance(phase, watermark)
+		}
+	}
+
+	return total, nil
+}
+
+// skipEmpty wraps write so a page with no items (a per-MR phase yields one
+// for every MR with no notes, approvals or commits; paginated phases never
+// yield the empty page that ends pagination) doesn't touch the sink at all.
+func skipEmpty[T any](write func([]T) error) func([]T) error {
+	return func(items []T) error {
+		if len(items) == 0 {
+			return nil
+		}
+		return write(items)
+	}
+}
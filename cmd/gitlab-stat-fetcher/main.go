@@ -0,0 +1,32 @@
+package main
+
+import (
+	"log"
+	"os"
+
+	"github.com/joho/godotenv"
+	"github.com/urfave/cli/v2"
+)
+
+func main() {
+	if err := godotenv.Load(); err != nil && !os.IsNotExist(err) {
+		log.Fatalf("Error loading .env file: %v", err)
+	}
+
+	app := &cli.App{
+		Name:  "gitlab-stat-fetcher",
+		Usage: "Fetch commits, merge requests and discussions from GitLab for analytics",
+		Commands: []*cli.Command{
+			fetchCommand(),
+			resumeCommand(),
+			statusCommand(),
+			listProjectsCommand(),
+			exportCommand(),
+			configCommand(),
+		},
+	}
+
+	if err := app.Run(os.Args); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/cheggaaa/pb/v3"
+
+	gl "github.com/AlexeyBelezeko/GitlabStatFetcher/pkg/gitlab"
+)
+
+// Progress starts one Bar per fetch phase (commits, MRs, discussions) and
+// mirrors every phase start/finish through slog as structured events, so a
+// downstream pipeline can consume them instead of scraping stdout. Drawing
+// bars and printing human banners/summaries are controlled independently:
+// --no-progress drops only the bars, --silent drops both.
+type Progress struct {
+	quiet  bool // --silent: suppress banners/summaries too
+	noBars bool // --silent or --no-progress: suppress drawing bars
+	logger *slog.Logger
+}
+
+// NewProgress builds a Progress. noBars suppresses drawing bars; quiet
+// additionally suppresses the human banners/summaries fetchAll prints.
+// Phase events are always logged regardless of either.
+func NewProgress(quiet, noBars bool) *Progress {
+	return &Progress{
+		quiet:  quiet,
+		noBars: noBars || quiet,
+		logger: slog.New(slog.NewJSONHandler(os.Stderr, nil)),
+	}
+}
+
+// Bar is one phase's progress indicator: a bounded bar when the total is
+// known up front (discussions, sized by MR count), or an open-ended
+// spinner with a running count and items/sec otherwise (commits, MRs,
+// whose total page count isn't known until pagination ends).
+type Bar struct {
+	phase     string
+	projectID int64
+	bar       *pb.ProgressBar
+	logger    *slog.Logger
+	start     time.Time
+	count     int64
+}
+
+// StartPhase begins tracking a phase for projectID. total is the known
+// item count, or 0 if it's open-ended. It satisfies gl.ProgressReporter so a
+// Progress can be passed straight into gl.FetchOptions.
+func (p *Progress) StartPhase(phase string, projectID int64, total int) gl.PhaseReporter {
+	p.logger.Info("phase.start", "phase", phase, "project_id", projectID, "total", total)
+
+	b := &Bar{phase: phase, projectID: projectID, logger: p.logger, start: time.Now()}
+	if p.noBars {
+		return b
+	}
+
+	if total > 0 {
+		b.bar = pb.StartNew(total)
+	} else {
+		tmpl := fmt.Sprintf(`{{ "%s:" }} {{counters . }} ({{speed . "%%s/s" }})`, phase)
+		b.bar = pb.ProgressBarTemplate(tmpl).Start(0)
+	}
+	return b
+}
+
+// Silent reports whether human banners/summaries should be suppressed
+// (--silent only; --no-progress alone still prints them).
+func (p *Progress) Silent() bool { return p.quiet }
+
+// Add records n more items processed for this phase.
+func (b *Bar) Add(n int) {
+	atomic.AddInt64(&b.count, int64(n))
+	if b.bar != nil {
+		b.bar.Add(n)
+	}
+}
+
+// Finish closes the bar and logs the phase's final count and duration.
+func (b *Bar) Finish() {
+	if b.bar != nil {
+		b.bar.Finish()
+	}
+	b.logger.Info("phase.finish", "phase", b.phase, "project_id", b.projectID,
+		"count", atomic.LoadInt64(&b.count), "elapsed", time.Since(b.start).String())
+}
@@ -0,0 +1,147 @@
+package gitlab
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"maps"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Fetch phase names used as keys into a Checkpoint's Pages map. Exported so
+// cmd can resume a fetch at the same phases this package records.
+const (
+	PhaseCommits     = "commits"
+	PhaseMRs         = "merge_requests"
+	PhasePipelines   = "pipelines"
+	PhaseIssues      = "issues"
+	PhaseDiscussions = "discussions"
+	PhaseApprovals   = "approvals"
+	PhaseMRCommits   = "mr_commits"
+)
+
+// Checkpoint records, per fetch phase, the last page (or index, for the
+// per-MR phases) of projectID that has been durably written to the sink.
+// It's saved to dataDir/.checkpoints/<project_id>.json after every page so
+// a run killed mid-project can resume from last_page+1 instead of
+// re-downloading everything .fetched_projects would otherwise discard. It
+// also carries the MR IIDs the MRs phase has discovered so far, since the
+// per-MR phases (discussions, approvals, MR commits) need that list even
+// when resuming after the MRs phase itself has already finished.
+type Checkpoint struct {
+	mu        sync.Mutex
+	dataDir   string
+	ProjectID int64            `json:"project_id"`
+	Pages     map[string]int64 `json:"pages"`
+	MRIIDs    []int64          `json:"mr_iids,omitempty"`
+}
+
+// LoadCheckpoint reads projectID's checkpoint, or returns an empty one if
+// none exists yet (a fresh fetch) or the file is unreadable.
+func LoadCheckpoint(dataDir string, projectID int64) *Checkpoint {
+	cp := &Checkpoint{dataDir: dataDir, ProjectID: projectID, Pages: map[string]int64{}}
+
+	data, err := os.ReadFile(checkpointPath(dataDir, projectID))
+	if err != nil {
+		return cp
+	}
+	if err := json.Unmarshal(data, cp); err != nil {
+		log.Printf("Error reading checkpoint for project %d, starting fresh: %v", projectID, err)
+		return &Checkpoint{dataDir: dataDir, ProjectID: projectID, Pages: map[string]int64{}}
+	}
+	cp.dataDir = dataDir
+	if cp.Pages == nil {
+		cp.Pages = map[string]int64{}
+	}
+	return cp
+}
+
+// LastPage returns the last page of phase already persisted for this
+// project, or 0 if that phase hasn't been started (or finished) yet.
+func (c *Checkpoint) LastPage(phase string) int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.Pages[phase]
+}
+
+// SavedMRIIDs returns the MR IIDs recorded by the most recent SetMRIIDs
+// call, or nil if the MRs phase hasn't written any yet this project.
+func (c *Checkpoint) SavedMRIIDs() []int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]int64(nil), c.MRIIDs...)
+}
+
+// Advance records that phase has now been persisted through page and
+// flushes the checkpoint to disk, if page is past what's already recorded.
+// Callers only call this once page (and every page before it) is known to
+// be contiguous, e.g. via a PageTracker, so a resumed fetch never skips a
+// page that failed to persist.
+func (c *Checkpoint) Advance(phase string, page int64) {
+	c.mu.Lock()
+	if page <= c.Pages[phase] {
+		c.mu.Unlock()
+		return
+	}
+	c.Pages[phase] = page
+	c.mu.Unlock()
+
+	c.flush()
+}
+
+// SetMRIIDs records the MR IIDs the MRs phase has discovered so far and
+// flushes the checkpoint to disk, so a per-MR phase resuming after the MRs
+// phase has already finished still knows which MRs to fetch per-MR data
+// for without re-paginating merge requests that were already persisted.
+func (c *Checkpoint) SetMRIIDs(mrIIDs []int64) {
+	c.mu.Lock()
+	c.MRIIDs = append([]int64(nil), mrIIDs...)
+	c.mu.Unlock()
+
+	c.flush()
+}
+
+// flush writes the checkpoint's current state to disk.
+func (c *Checkpoint) flush() {
+	c.mu.Lock()
+	snapshot := checkpointFile{ProjectID: c.ProjectID, Pages: maps.Clone(c.Pages), MRIIDs: c.MRIIDs}
+	c.mu.Unlock()
+
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		log.Printf("Error marshaling checkpoint for project %d: %v", c.ProjectID, err)
+		return
+	}
+
+	path := checkpointPath(c.dataDir, c.ProjectID)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		log.Printf("Error creating checkpoint directory: %v", err)
+		return
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		log.Printf("Error writing checkpoint for project %d: %v", c.ProjectID, err)
+	}
+}
+
+// checkpointFile is the on-disk shape of a Checkpoint, without the mutex
+// and dataDir that json.Marshal would otherwise need to skip over anyway.
+type checkpointFile struct {
+	ProjectID int64            `json:"project_id"`
+	Pages     map[string]int64 `json:"pages"`
+	MRIIDs    []int64          `json:"mr_iids,omitempty"`
+}
+
+// ClearCheckpoint removes projectID's checkpoint once its fetch completes
+// in full, so a later re-fetch of that project (if it's ever removed from
+// .fetched_projects) starts every phase over rather than resuming midway.
+func ClearCheckpoint(dataDir string, projectID int64) {
+	if err := os.Remove(checkpointPath(dataDir, projectID)); err != nil && !os.IsNotExist(err) {
+		log.Printf("Error removing checkpoint for project %d: %v", projectID, err)
+	}
+}
+
+func checkpointPath(dataDir string, projectID int64) string {
+	return filepath.Join(dataDir, ".checkpoints", fmt.Sprintf("%d.json", projectID))
+}
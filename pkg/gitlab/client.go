@@ -0,0 +1,36 @@
+package gitlab
+
+import (
+	"math"
+	"net/http"
+	"time"
+
+	glapi "gitlab.com/gitlab-org/api/client-go"
+)
+
+// ClientOptions configures the HTTP transport chain NewClient installs:
+// an on-disk response cache (outermost, so cache hits never touch the rate
+// limiter) wrapping a token-bucket rate limiter.
+type ClientOptions struct {
+	CacheDir string
+	CacheTTL time.Duration
+	RPS      float64
+	Burst    int
+}
+
+// NewClient builds a *Client against baseURL, authenticated with token,
+// whose requests are cached under opts.CacheDir and throttled to opts.RPS.
+// An opts.RPS of 0 (the zero value) means unthrottled, for callers such as
+// list-projects that don't go through the paginated fetch workers.
+func NewClient(token, baseURL string, opts ClientOptions) (*Client, error) {
+	rps, burst := opts.RPS, opts.Burst
+	if rps <= 0 {
+		rps, burst = math.MaxFloat64, 1
+	}
+
+	rateLimited := newRateLimitTransport(http.DefaultTransport, rps, burst)
+	cached := newHTTPCacheTransport(rateLimited, opts.CacheDir, opts.CacheTTL)
+	httpClient := &http.Client{Transport: cached}
+
+	return glapi.NewClient(token, glapi.WithBaseURL(baseURL), glapi.WithHTTPClient(httpClient))
+}
@@ -0,0 +1,204 @@
+package gitlab
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"time"
+
+	glapi "gitlab.com/gitlab-org/api/client-go"
+)
+
+// MRApproval is the approval state of one merge request at fetch time.
+type MRApproval struct {
+	ProjectID         int64
+	MRIID             int64
+	ApprovalsRequired int
+	ApprovalsLeft     int
+	Approved          bool
+	ApprovedBy        []string
+}
+
+// MRCommit is one row of the join table between a merge request and the
+// commits it's made up of.
+type MRCommit struct {
+	ProjectID int64
+	MRIID     int64
+	CommitID  string
+}
+
+// FetchPipelines pages through projectID's pipelines updated after opts.Since.
+func (f *Fetcher) FetchPipelines(ctx context.Context, projectID int64, opts FetchOptions) iter.Seq2[Page[*Pipeline], error] {
+	var bar PhaseReporter
+	if opts.Progress != nil {
+		bar = opts.Progress.StartPhase("pipelines", projectID, 0)
+	}
+
+	pages := paginate(ctx, f.workers, opts.startPage(), func(page int64) ([]*Pipeline, error) {
+		return f.fetchPipelinePage(projectID, page, opts.Since)
+	})
+
+	return func(yield func(Page[*Pipeline], error) bool) {
+		if bar != nil {
+			defer bar.Finish()
+		}
+		for p, err := range pages {
+			if bar != nil && err == nil {
+				bar.Add(len(p.Items))
+			}
+			if !yield(p, err) {
+				return
+			}
+		}
+	}
+}
+
+func (f *Fetcher) fetchPipelinePage(projectID, page int64, sinceDate *time.Time) ([]*Pipeline, error) {
+	opts := &glapi.ListProjectPipelinesOptions{
+		ListOptions:  glapi.ListOptions{Page: page, PerPage: 100},
+		UpdatedAfter: sinceDate,
+	}
+
+	pipelines, _, err := f.client.Pipelines.ListProjectPipelines(projectID, opts)
+	if err != nil {
+		return nil, fmt.Errorf("fetching pipelines: %w", err)
+	}
+	return pipelines, nil
+}
+
+// FetchIssues pages through projectID's issues created after opts.Since.
+func (f *Fetcher) FetchIssues(ctx context.Context, projectID int64, opts FetchOptions) iter.Seq2[Page[*Issue], error] {
+	var bar PhaseReporter
+	if opts.Progress != nil {
+		bar = opts.Progress.StartPhase("issues", projectID, 0)
+	}
+
+	pages := paginate(ctx, f.workers, opts.startPage(), func(page int64) ([]*Issue, error) {
+		return f.fetchIssuePage(projectID, page, opts.Since)
+	})
+
+	return func(yield func(Page[*Issue], error) bool) {
+		if bar != nil {
+			defer bar.Finish()
+		}
+		for p, err := range pages {
+			if bar != nil && err == nil {
+				bar.Add(len(p.Items))
+			}
+			if !yield(p, err) {
+				return
+			}
+		}
+	}
+}
+
+func (f *Fetcher) fetchIssuePage(projectID, page int64, sinceDate *time.Time) ([]*Issue, error) {
+	opts := &glapi.ListProjectIssuesOptions{
+		ListOptions:  glapi.ListOptions{Page: page, PerPage: 100},
+		CreatedAfter: sinceDate,
+	}
+
+	issues, _, err := f.client.Issues.ListProjectIssues(projectID, opts)
+	if err != nil {
+		return nil, fmt.Errorf("fetching issues: %w", err)
+	}
+	return issues, nil
+}
+
+// FetchMRApprovals fetches the current approval state of each of
+// mrIIDs[startIdx-1:] (startIdx is 1-based, as fanOut numbers items).
+func (f *Fetcher) FetchMRApprovals(ctx context.Context, projectID int64, mrIIDs []int64, startIdx int64, progress ProgressReporter) iter.Seq2[Page[*MRApproval], error] {
+	var bar PhaseReporter
+	if progress != nil {
+		bar = progress.StartPhase("approvals", projectID, len(mrIIDs))
+	}
+
+	results := fanOut(ctx, f.workers, startIdx, int64(len(mrIIDs)), func(idx int64) ([]*MRApproval, error) {
+		approval, err := f.fetchMRApproval(projectID, mrIIDs[idx])
+		if err != nil {
+			return nil, err
+		}
+		if bar != nil {
+			bar.Add(1)
+		}
+		return []*MRApproval{approval}, nil
+	})
+
+	return func(yield func(Page[*MRApproval], error) bool) {
+		if bar != nil {
+			defer bar.Finish()
+		}
+		for p, err := range results {
+			if !yield(p, err) {
+				return
+			}
+		}
+	}
+}
+
+func (f *Fetcher) fetchMRApproval(projectID, mrIID int64) (*MRApproval, error) {
+	config, _, err := f.client.MergeRequestApprovals.GetConfiguration(projectID, mrIID, nil)
+	if err != nil {
+		return nil, fmt.Errorf("fetching merge request approvals: %w", err)
+	}
+
+	approvedBy := make([]string, 0, len(config.ApprovedBy))
+	for _, a := range config.ApprovedBy {
+		if a.User != nil {
+			approvedBy = append(approvedBy, a.User.Username)
+		}
+	}
+
+	return &MRApproval{
+		ProjectID:         projectID,
+		MRIID:             mrIID,
+		ApprovalsRequired: int(config.ApprovalsRequired),
+		ApprovalsLeft:     int(config.ApprovalsLeft),
+		Approved:          config.Approved,
+		ApprovedBy:        approvedBy,
+	}, nil
+}
+
+// FetchMRCommits fetches the commits making up each of mrIIDs[startIdx-1:]
+// (startIdx is 1-based, as fanOut numbers items).
+func (f *Fetcher) FetchMRCommits(ctx context.Context, projectID int64, mrIIDs []int64, startIdx int64, progress ProgressReporter) iter.Seq2[Page[*MRCommit], error] {
+	var bar PhaseReporter
+	if progress != nil {
+		bar = progress.StartPhase("mr commits", projectID, len(mrIIDs))
+	}
+
+	results := fanOut(ctx, f.workers, startIdx, int64(len(mrIIDs)), func(idx int64) ([]*MRCommit, error) {
+		commits, err := f.fetchMRCommits(projectID, mrIIDs[idx])
+		if err != nil {
+			return nil, err
+		}
+		if bar != nil {
+			bar.Add(1)
+		}
+		return commits, nil
+	})
+
+	return func(yield func(Page[*MRCommit], error) bool) {
+		if bar != nil {
+			defer bar.Finish()
+		}
+		for p, err := range results {
+			if !yield(p, err) {
+				return
+			}
+		}
+	}
+}
+
+func (f *Fetcher) fetchMRCommits(projectID, mrIID int64) ([]*MRCommit, error) {
+	commits, _, err := f.client.MergeRequests.GetMergeRequestCommits(projectID, mrIID, nil)
+	if err != nil {
+		return nil, fmt.Errorf("fetching merge request commits: %w", err)
+	}
+
+	rows := make([]*MRCommit, 0, len(commits))
+	for _, c := range commits {
+		rows = append(rows, &MRCommit{ProjectID: projectID, MRIID: mrIID, CommitID: c.ID})
+	}
+	return rows, nil
+}
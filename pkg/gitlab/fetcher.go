@@ -0,0 +1,345 @@
+package gitlab
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	glapi "gitlab.com/gitlab-org/api/client-go"
+)
+
+// ProgressReporter receives progress updates from a Fetcher. cmd's Progress
+// implements it so fetch progress can drive bars without this package
+// depending on a display library.
+type ProgressReporter interface {
+	StartPhase(phase string, projectID int64, total int) PhaseReporter
+}
+
+// PhaseReporter tracks one phase (commits, MRs, discussions) of a fetch.
+type PhaseReporter interface {
+	Add(n int)
+	Finish()
+}
+
+// FetchOptions controls what a Fetcher method fetches. Progress may be nil,
+// in which case no progress is reported. StartPage resumes pagination at
+// that page instead of page 1, for a Fetcher driven by a checkpoint.
+type FetchOptions struct {
+	Since     *time.Time
+	Progress  ProgressReporter
+	StartPage int64
+}
+
+// startPage returns opts.StartPage, defaulting to page 1 for a fresh fetch.
+func (opts FetchOptions) startPage() int64 {
+	if opts.StartPage <= 0 {
+		return 1
+	}
+	return opts.StartPage
+}
+
+// Page is one page of paginated results together with its page number, so a
+// caller can durably checkpoint "resume from Number+1" once Items are
+// persisted. For the per-MR fetchers (discussions, approvals, MR commits)
+// Number is the 1-based position in the mrIIDs slice (slice index + 1)
+// rather than a GitLab page.
+type Page[T any] struct {
+	Number int64
+	Items  []T
+}
+
+// Fetcher pages through a project's commits, merge requests and discussions
+// using a pool of workers, each claiming the next unfetched page. Every
+// method returns an iter.Seq2 of Page so a caller can stream each page into
+// a sink and checkpoint it individually instead of holding the whole fetch
+// in memory, and stops early once ctx is canceled.
+type Fetcher struct {
+	client  *Client
+	workers int
+}
+
+// NewFetcher builds a Fetcher that pages with the given number of
+// concurrent workers.
+func NewFetcher(client *Client, workers int) *Fetcher {
+	return &Fetcher{client: client, workers: workers}
+}
+
+// FetchCommits pages through projectID's commits since opts.Since.
+func (f *Fetcher) FetchCommits(ctx context.Context, projectID int64, opts FetchOptions) iter.Seq2[Page[*Commit], error] {
+	var bar PhaseReporter
+	if opts.Progress != nil {
+		bar = opts.Progress.StartPhase("commits", projectID, 0)
+	}
+
+	pages := paginate(ctx, f.workers, opts.startPage(), func(page int64) ([]*Commit, error) {
+		return f.fetchCommitPage(projectID, page, opts.Since)
+	})
+
+	return func(yield func(Page[*Commit], error) bool) {
+		if bar != nil {
+			defer bar.Finish()
+		}
+		for p, err := range pages {
+			if bar != nil && err == nil {
+				bar.Add(len(p.Items))
+			}
+			if !yield(p, err) {
+				return
+			}
+		}
+	}
+}
+
+func (f *Fetcher) fetchCommitPage(projectID, page int64, sinceDate *time.Time) ([]*Commit, error) {
+	opts := &glapi.ListCommitsOptions{
+		WithStats:   glapi.Ptr(true),
+		ListOptions: glapi.ListOptions{Page: page, PerPage: 100},
+		Since:       sinceDate,
+	}
+
+	commits, _, err := f.client.Commits.ListCommits(projectID, opts)
+	if err != nil {
+		return nil, fmt.Errorf("fetching commits: %w", err)
+	}
+	return commits, nil
+}
+
+// FetchMRs pages through projectID's merge requests created after opts.Since.
+func (f *Fetcher) FetchMRs(ctx context.Context, projectID int64, opts FetchOptions) iter.Seq2[Page[*BasicMergeRequest], error] {
+	var bar PhaseReporter
+	if opts.Progress != nil {
+		bar = opts.Progress.StartPhase("merge requests", projectID, 0)
+	}
+
+	pages := paginate(ctx, f.workers, opts.startPage(), func(page int64) ([]*BasicMergeRequest, error) {
+		return f.fetchMRPage(projectID, page, opts.Since)
+	})
+
+	return func(yield func(Page[*BasicMergeRequest], error) bool) {
+		if bar != nil {
+			defer bar.Finish()
+		}
+		for p, err := range pages {
+			if bar != nil && err == nil {
+				bar.Add(len(p.Items))
+			}
+			if !yield(p, err) {
+				return
+			}
+		}
+	}
+}
+
+func (f *Fetcher) fetchMRPage(projectID, page int64, sinceDate *time.Time) ([]*BasicMergeRequest, error) {
+	opts := &glapi.ListProjectMergeRequestsOptions{
+		State:        glapi.Ptr("all"),
+		ListOptions:  glapi.ListOptions{Page: page, PerPage: 100},
+		CreatedAfter: sinceDate,
+	}
+
+	mrs, _, err := f.client.MergeRequests.ListProjectMergeRequests(projectID, opts)
+	if err != nil {
+		return nil, fmt.Errorf("fetching merge requests: %w", err)
+	}
+	return mrs, nil
+}
+
+// FetchDiscussions fetches the notes on each of mrIIDs[startIdx-1:]
+// (startIdx is 1-based, as fanOut numbers items), progress being sized by
+// len(mrIIDs) since that total is known up front.
+func (f *Fetcher) FetchDiscussions(ctx context.Context, projectID int64, mrIIDs []int64, startIdx int64, progress ProgressReporter) iter.Seq2[Page[*Note], error] {
+	var bar PhaseReporter
+	if progress != nil {
+		bar = progress.StartPhase("discussions", projectID, len(mrIIDs))
+	}
+
+	items := fanOut(ctx, f.workers, startIdx, int64(len(mrIIDs)), func(idx int64) ([]*Note, error) {
+		return f.fetchMRDiscussions(projectID, mrIIDs[idx])
+	})
+
+	return func(yield func(Page[*Note], error) bool) {
+		if bar != nil {
+			defer bar.Finish()
+		}
+		for p, err := range items {
+			if bar != nil && err == nil {
+				bar.Add(1)
+			}
+			if !yield(p, err) {
+				return
+			}
+		}
+	}
+}
+
+func (f *Fetcher) fetchMRDiscussions(projectID, mrIID int64) ([]*Note, error) {
+	discussions, _, err := f.client.Discussions.ListMergeRequestDiscussions(projectID, mrIID, nil)
+	if err != nil {
+		return nil, fmt.Errorf("fetching discussions: %w", err)
+	}
+	notes := make([]*Note, 0, len(discussions))
+	for _, d := range discussions {
+		notes = append(notes, d.Notes...)
+	}
+	return notes, nil
+}
+
+// fanOut runs fetch once per 1-indexed item number in [startIdx, n] across
+// workers goroutines, for per-MR lookups (discussions, approvals, commits)
+// where the total is known up front instead of discovered via an empty
+// page. fetch receives the corresponding 0-based slice index (number-1).
+// Each item's results come back as a Page whose Number is that 1-indexed
+// number, matching paginate's page numbering, so a caller checkpoints
+// "resume from number+1" the same way for both. Like paginate, the first
+// error stops every worker and is yielded instead of a page, so a caller
+// can tell "fetched everything" from "gave up partway through".
+func fanOut[T any](ctx context.Context, workers int, startIdx, n int64, fetch func(idx int64) ([]T, error)) iter.Seq2[Page[T], error] {
+	type fanResult struct {
+		page Page[T]
+		err  error
+	}
+
+	return func(yield func(Page[T], error) bool) {
+		var (
+			next    = startIdx
+			stop    int32
+			results = make(chan fanResult, workers*2)
+			wg      sync.WaitGroup
+		)
+
+		for i := 0; i < workers; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for {
+					if atomic.LoadInt32(&stop) == 1 || ctx.Err() != nil {
+						return
+					}
+					num := atomic.AddInt64(&next, 1) - 1
+					if num > n {
+						return
+					}
+					items, err := fetch(num - 1)
+					if err != nil {
+						atomic.StoreInt32(&stop, 1)
+						results <- fanResult{err: err}
+						return
+					}
+					results <- fanResult{page: Page[T]{Number: num, Items: items}}
+				}
+			}()
+		}
+
+		go func() {
+			wg.Wait()
+			close(results)
+		}()
+
+		for res := range results {
+			if res.err != nil {
+				var zero Page[T]
+				yield(zero, res.err)
+				return
+			}
+			if !yield(res.page, nil) {
+				return
+			}
+		}
+	}
+}
+
+// paginate runs fetchPage with workers goroutines, each claiming the next
+// unfetched page from a shared counter starting at startPage, until a page
+// comes back empty or ctx is canceled. Pages are streamed out of order of
+// completion (not page order); a caller that needs a contiguous resume
+// point tracks that itself with a PageTracker.
+func paginate[T any](ctx context.Context, workers int, startPage int64, fetchPage func(page int64) ([]T, error)) iter.Seq2[Page[T], error] {
+	type pageResult struct {
+		page Page[T]
+		err  error
+	}
+
+	return func(yield func(Page[T], error) bool) {
+		var (
+			nextPage = startPage
+			stop     int32
+			results  = make(chan pageResult, workers*2)
+			wg       sync.WaitGroup
+		)
+
+		for i := 0; i < workers; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for {
+					if atomic.LoadInt32(&stop) == 1 || ctx.Err() != nil {
+						return
+					}
+					page := atomic.AddInt64(&nextPage, 1) - 1
+
+					items, err := fetchPage(page)
+					if err != nil {
+						atomic.StoreInt32(&stop, 1)
+						results <- pageResult{err: err}
+						return
+					}
+					if len(items) == 0 {
+						atomic.StoreInt32(&stop, 1)
+						return
+					}
+					results <- pageResult{page: Page[T]{Number: page, Items: items}}
+				}
+			}()
+		}
+
+		go func() {
+			wg.Wait()
+			close(results)
+		}()
+
+		for res := range results {
+			if res.err != nil {
+				var zero Page[T]
+				yield(zero, res.err)
+				return
+			}
+			if !yield(res.page, nil) {
+				return
+			}
+		}
+	}
+}
+
+// PageTracker computes the highest contiguous page/index completed so
+// far, so a caller can checkpoint "resume from here" even though paginate
+// and fanOut deliver results out of completion order across workers.
+type PageTracker struct {
+	mu        sync.Mutex
+	done      map[int64]bool
+	watermark int64
+}
+
+// NewPageTracker starts tracking from startPage, i.e. startPage-1 is
+// already known complete.
+func NewPageTracker(startPage int64) *PageTracker {
+	return &PageTracker{done: make(map[int64]bool), watermark: startPage - 1}
+}
+
+// Mark records page as complete and reports the new contiguous watermark
+// if it advanced past what had already been recorded.
+func (t *PageTracker) Mark(page int64) (int64, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.done[page] = true
+	advanced := false
+	for t.done[t.watermark+1] {
+		t.watermark++
+		delete(t.done, t.watermark)
+		advanced = true
+	}
+	return t.watermark, advanced
+}
@@ -0,0 +1,63 @@
+package gitlab
+
+import "testing"
+
+func TestPageTrackerMarkInOrder(t *testing.T) {
+	tracker := NewPageTracker(1)
+
+	watermark, advanced := tracker.Mark(1)
+	if !advanced || watermark != 1 {
+		t.Fatalf("Mark(1) = (%d, %v), want (1, true)", watermark, advanced)
+	}
+
+	watermark, advanced = tracker.Mark(2)
+	if !advanced || watermark != 2 {
+		t.Fatalf("Mark(2) = (%d, %v), want (2, true)", watermark, advanced)
+	}
+}
+
+func TestPageTrackerMarkOutOfOrder(t *testing.T) {
+	tracker := NewPageTracker(1)
+
+	// Page 3 finishes before page 2: nothing contiguous with the
+	// watermark yet, so it shouldn't advance.
+	watermark, advanced := tracker.Mark(3)
+	if advanced || watermark != 0 {
+		t.Fatalf("Mark(3) = (%d, %v), want (0, false)", watermark, advanced)
+	}
+
+	// Page 1 closes the gap at the start: watermark advances to 1.
+	watermark, advanced = tracker.Mark(1)
+	if !advanced || watermark != 1 {
+		t.Fatalf("Mark(1) = (%d, %v), want (1, true)", watermark, advanced)
+	}
+
+	// Page 2 now makes 1,2,3 contiguous, so the watermark jumps straight
+	// to 3 even though 3 completed first.
+	watermark, advanced = tracker.Mark(2)
+	if !advanced || watermark != 3 {
+		t.Fatalf("Mark(2) = (%d, %v), want (3, true)", watermark, advanced)
+	}
+}
+
+func TestPageTrackerMarkDuplicate(t *testing.T) {
+	tracker := NewPageTracker(1)
+	tracker.Mark(1)
+
+	// Re-marking a page already folded into the watermark shouldn't
+	// report an advance.
+	watermark, advanced := tracker.Mark(1)
+	if advanced || watermark != 1 {
+		t.Fatalf("re-Mark(1) = (%d, %v), want (1, false)", watermark, advanced)
+	}
+}
+
+func TestNewPageTrackerResumesFromStartPage(t *testing.T) {
+	// Resuming from page 5 means pages 1-4 are already known complete.
+	tracker := NewPageTracker(5)
+
+	watermark, advanced := tracker.Mark(5)
+	if !advanced || watermark != 5 {
+		t.Fatalf("Mark(5) = (%d, %v), want (5, true)", watermark, advanced)
+	}
+}
@@ -0,0 +1,138 @@
+package gitlab
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// cachedResponse is the on-disk representation of a cached HTTP response,
+// keyed by request URL (including query string). Storing ETag/Last-Modified
+// alongside the body lets a later request revalidate with a conditional GET
+// instead of re-fetching and re-decoding a page that hasn't changed.
+type cachedResponse struct {
+	StatusCode   int         `json:"status_code"`
+	Header       http.Header `json:"header"`
+	Body         []byte      `json:"body"`
+	ETag         string      `json:"etag,omitempty"`
+	LastModified string      `json:"last_modified,omitempty"`
+	StoredAt     time.Time   `json:"stored_at"`
+}
+
+// httpCacheTransport is an http.RoundTripper that caches GET responses
+// under cacheDir, one file per request URL. Within ttl a cache hit is
+// served without touching the network; once stale it's revalidated with
+// If-None-Match/If-Modified-Since so a 304 still avoids a full re-fetch.
+// This turns repeated runs against already-fetched projects into a true
+// incremental sync instead of redoing every request from scratch.
+type httpCacheTransport struct {
+	next     http.RoundTripper
+	cacheDir string
+	ttl      time.Duration
+}
+
+func newHTTPCacheTransport(next http.RoundTripper, cacheDir string, ttl time.Duration) *httpCacheTransport {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	os.MkdirAll(cacheDir, 0755)
+	return &httpCacheTransport{next: next, cacheDir: cacheDir, ttl: ttl}
+}
+
+func (t *httpCacheTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet {
+		return t.next.RoundTrip(req)
+	}
+
+	key := cacheKey(req)
+	cached, _ := t.load(key)
+
+	if cached != nil && t.ttl > 0 && time.Since(cached.StoredAt) < t.ttl {
+		return cached.toResponse(req), nil
+	}
+
+	if cached != nil {
+		if cached.ETag != "" {
+			req.Header.Set("If-None-Match", cached.ETag)
+		}
+		if cached.LastModified != "" {
+			req.Header.Set("If-Modified-Since", cached.LastModified)
+		}
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusNotModified && cached != nil {
+		resp.Body.Close()
+		cached.StoredAt = time.Now()
+		_ = t.store(key, *cached)
+		return cached.toResponse(req), nil
+	}
+
+	if resp.StatusCode == http.StatusOK {
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+
+		entry := cachedResponse{
+			StatusCode:   resp.StatusCode,
+			Header:       resp.Header.Clone(),
+			Body:         body,
+			ETag:         resp.Header.Get("ETag"),
+			LastModified: resp.Header.Get("Last-Modified"),
+			StoredAt:     time.Now(),
+		}
+		if entry.ETag != "" || entry.LastModified != "" {
+			_ = t.store(key, entry)
+		}
+	}
+
+	return resp, nil
+}
+
+func (t *httpCacheTransport) load(key string) (*cachedResponse, error) {
+	data, err := os.ReadFile(filepath.Join(t.cacheDir, key))
+	if err != nil {
+		return nil, err
+	}
+	var entry cachedResponse
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+func (t *httpCacheTransport) store(key string, entry cachedResponse) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(t.cacheDir, key), data, 0644)
+}
+
+func (c *cachedResponse) toResponse(req *http.Request) *http.Response {
+	return &http.Response{
+		StatusCode: c.StatusCode,
+		Status:     http.StatusText(c.StatusCode),
+		Header:     c.Header,
+		Body:       io.NopCloser(bytes.NewReader(c.Body)),
+		Request:    req,
+	}
+}
+
+func cacheKey(req *http.Request) string {
+	sum := sha256.Sum256([]byte(req.URL.String()))
+	return hex.EncodeToString(sum[:]) + ".json"
+}
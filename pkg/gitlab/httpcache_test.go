@@ -0,0 +1,87 @@
+package gitlab
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// countingETagServer serves body for every GET, returning 304 (and no
+// body) whenever If-None-Match matches the fixed ETag it hands out.
+type countingETagServer struct {
+	requests int
+	notMod   int
+}
+
+func (s *countingETagServer) handler(body string, etag string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.requests++
+		if r.Header.Get("If-None-Match") == etag {
+			s.notMod++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		w.Write([]byte(body))
+	}
+}
+
+func TestHTTPCacheServesFreshEntryWithoutRequest(t *testing.T) {
+	srv := &countingETagServer{}
+	ts := httptest.NewServer(srv.handler("hello", `"v1"`))
+	defer ts.Close()
+
+	cacheDir := t.TempDir()
+	transport := newHTTPCacheTransport(http.DefaultTransport, cacheDir, time.Hour)
+	client := &http.Client{Transport: transport}
+
+	for i := 0; i < 3; i++ {
+		resp, err := client.Get(ts.URL)
+		if err != nil {
+			t.Fatalf("GET #%d: %v", i, err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if string(body) != "hello" {
+			t.Fatalf("GET #%d body = %q, want %q", i, body, "hello")
+		}
+	}
+
+	// Within ttl, only the first request should ever reach the server.
+	if srv.requests != 1 {
+		t.Fatalf("server saw %d requests, want 1 (rest served from cache)", srv.requests)
+	}
+}
+
+func TestHTTPCacheRevalidatesStaleEntryWith304(t *testing.T) {
+	srv := &countingETagServer{}
+	ts := httptest.NewServer(srv.handler("hello", `"v1"`))
+	defer ts.Close()
+
+	cacheDir := t.TempDir()
+	// ttl of 0 means every request is immediately stale and must
+	// revalidate.
+	transport := newHTTPCacheTransport(http.DefaultTransport, cacheDir, 0)
+	client := &http.Client{Transport: transport}
+
+	for i := 0; i < 3; i++ {
+		resp, err := client.Get(ts.URL)
+		if err != nil {
+			t.Fatalf("GET #%d: %v", i, err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if string(body) != "hello" {
+			t.Fatalf("GET #%d body = %q, want %q (from cache on 304)", i, body, "hello")
+		}
+	}
+
+	if srv.requests != 3 {
+		t.Fatalf("server saw %d requests, want 3 (every request revalidates)", srv.requests)
+	}
+	if srv.notMod != 2 {
+		t.Fatalf("server returned %d 304s, want 2 (all but the first)", srv.notMod)
+	}
+}
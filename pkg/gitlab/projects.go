@@ -0,0 +1,138 @@
+package gitlab
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+
+	glapi "gitlab.com/gitlab-org/api/client-go"
+)
+
+// extractPath extracts the path from a GitLab URL (works for both projects and groups)
+func extractPath(inputURL, baseURL string) (string, error) {
+	parsedURL, err := url.Parse(inputURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid URL: %v", err)
+	}
+	parsedBase, err := url.Parse(baseURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid base URL: %v", err)
+	}
+	if parsedURL.Host != parsedBase.Host {
+		return "", fmt.Errorf("URL host %s does not match GitLab base URL %s", parsedURL.Host, parsedBase.Host)
+	}
+	p := strings.TrimPrefix(parsedURL.Path, "/")
+	return p, nil
+}
+
+// recursivelyFetchProjectFromPath returns list of projects from group path and all subgroups. If path is a project
+// them returns one project.
+func recursivelyFetchProjectFromPath(client *Client, path string) ([]*Project, error) {
+	project, _, err := client.Projects.GetProject(path, nil)
+	if err == nil {
+		return []*Project{project}, err
+	}
+
+	group, _, err := client.Groups.GetGroup(path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching group: %v", err)
+	}
+
+	listSubgroupsOpts := &glapi.ListSubGroupsOptions{
+		ListOptions: glapi.ListOptions{
+			PerPage: 100,
+			Page:    1,
+		},
+	}
+
+	var allProjects []*Project
+	for {
+		groups, resp, err := client.Groups.ListSubGroups(group.ID, listSubgroupsOpts)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching list subgroups: %v", err)
+		}
+
+		for _, subgroup := range groups {
+			subgroupProjects, err := listGroupProjects(client, subgroup.ID)
+			if err != nil {
+				return nil, fmt.Errorf("error fetching list subgroup projects: %v", err)
+			}
+			allProjects = append(allProjects, subgroupProjects...)
+		}
+
+		if resp.CurrentPage >= resp.TotalPages {
+			break
+		}
+		listSubgroupsOpts.Page = resp.NextPage
+	}
+
+	groupProjects, err := listGroupProjects(client, group.ID)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching list group projects: %v", err)
+	}
+	allProjects = append(allProjects, groupProjects...)
+
+	return allProjects, nil
+}
+
+func listGroupProjects(client *Client, groupID int64) ([]*Project, error) {
+	listProjectsOpts := &glapi.ListGroupProjectsOptions{
+		ListOptions: glapi.ListOptions{
+			PerPage: 100,
+			Page:    1,
+		},
+	}
+	var allProjects []*Project
+	for {
+		projects, resp, err := client.Groups.ListGroupProjects(groupID, listProjectsOpts)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching list projects: %v", err)
+		}
+
+		allProjects = append(allProjects, projects...)
+		if resp.NextPage == 0 {
+			break
+		}
+		listProjectsOpts.Page = resp.NextPage
+	}
+	return allProjects, nil
+}
+
+// resolveProjects expands urls (project or group URLs) into their
+// projects, then narrows the result by includeGlob/excludeGlob matched
+// against each project's PathWithNamespace.
+func resolveProjects(client *Client, gitlabBaseURL string, urls []string, includeGlob, excludeGlob string) ([]*Project, error) {
+	var allProjects []*Project
+	for _, u := range urls {
+		p, err := extractPath(u, gitlabBaseURL)
+		if err != nil {
+			return nil, err
+		}
+		projects, err := recursivelyFetchProjectFromPath(client, p)
+		if err != nil {
+			return nil, err
+		}
+		allProjects = append(allProjects, projects...)
+	}
+
+	if includeGlob == "" && excludeGlob == "" {
+		return allProjects, nil
+	}
+
+	filtered := make([]*Project, 0, len(allProjects))
+	for _, project := range allProjects {
+		if includeGlob != "" {
+			if ok, _ := path.Match(includeGlob, project.PathWithNamespace); !ok {
+				continue
+			}
+		}
+		if excludeGlob != "" {
+			if ok, _ := path.Match(excludeGlob, project.PathWithNamespace); ok {
+				continue
+			}
+		}
+		filtered = append(filtered, project)
+	}
+	return filtered, nil
+}
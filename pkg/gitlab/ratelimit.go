@@ -0,0 +1,130 @@
+package gitlab
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// rateLimitTransport is an http.RoundTripper that throttles outgoing
+// requests through a token-bucket limiter, and adapts that limiter's rate
+// from GitLab's RateLimit-* response headers so a worker pool sized for a
+// beefy GitLab.com doesn't 429 a small self-hosted instance. On a 429 (or
+// any Retry-After), it pauses every request until the reset deadline.
+type rateLimitTransport struct {
+	next    http.RoundTripper
+	limiter *rate.Limiter
+
+	mu        sync.Mutex
+	pausedTil time.Time
+}
+
+func newRateLimitTransport(next http.RoundTripper, rps float64, burst int) *rateLimitTransport {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &rateLimitTransport{next: next, limiter: rate.NewLimiter(rate.Limit(rps), burst)}
+}
+
+func (t *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.waitForPause(req.Context()); err != nil {
+		return nil, err
+	}
+	if err := t.limiter.Wait(req.Context()); err != nil {
+		return nil, err
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	t.adjustFromHeaders(resp.Header)
+
+	if resp.StatusCode == http.StatusTooManyRequests || resp.Header.Get("Retry-After") != "" {
+		until := retryAfter(resp.Header)
+		log.Printf("Rate limited (%d), pausing all workers until %s", resp.StatusCode, until.Format(time.RFC3339))
+		t.pauseUntil(until)
+	}
+
+	return resp, nil
+}
+
+func (t *rateLimitTransport) waitForPause(ctx context.Context) error {
+	t.mu.Lock()
+	until := t.pausedTil
+	t.mu.Unlock()
+
+	wait := time.Until(until)
+	if wait <= 0 {
+		return nil
+	}
+
+	select {
+	case <-time.After(wait):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (t *rateLimitTransport) pauseUntil(until time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if until.After(t.pausedTil) {
+		t.pausedTil = until
+	}
+}
+
+// adjustFromHeaders shrinks the limiter's rate once GitLab reports we're
+// close to exhausting our quota. It never raises the rate back above the
+// configured --rps: once bitten, stay cautious for the rest of the run.
+func (t *rateLimitTransport) adjustFromHeaders(header http.Header) {
+	remaining, ok := parseIntHeader(header, "RateLimit-Remaining")
+	if !ok {
+		return
+	}
+	limit, ok := parseIntHeader(header, "RateLimit-Limit")
+	if !ok || limit == 0 {
+		return
+	}
+	observed, _ := parseIntHeader(header, "RateLimit-Observed")
+
+	// Below 10% headroom, back off hard.
+	if float64(remaining)/float64(limit) < 0.1 {
+		newLimit := t.limiter.Limit() / 2
+		t.limiter.SetLimit(newLimit)
+		log.Printf("Rate limit headroom low (remaining=%d/%d, observed=%d), throttling to %.2f req/s",
+			remaining, limit, observed, float64(newLimit))
+	}
+}
+
+func parseIntHeader(header http.Header, name string) (int, bool) {
+	v := header.Get(name)
+	if v == "" {
+		return 0, false
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, false
+	}
+	return n, true
+}
+
+// retryAfter resolves the pause deadline for a 429, preferring
+// RateLimit-Reset (a Unix timestamp) and falling back to Retry-After
+// (seconds from now).
+func retryAfter(header http.Header) time.Time {
+	if reset, ok := parseIntHeader(header, "RateLimit-Reset"); ok {
+		return time.Unix(int64(reset), 0)
+	}
+	if retry, ok := parseIntHeader(header, "Retry-After"); ok {
+		return time.Now().Add(time.Duration(retry) * time.Second)
+	}
+	return time.Now().Add(time.Second)
+}
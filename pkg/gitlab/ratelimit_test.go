@@ -0,0 +1,73 @@
+package gitlab
+
+import (
+	"net/http"
+	"testing"
+
+	"golang.org/x/time/rate"
+)
+
+func TestAdjustFromHeadersThrottlesBelowTenPercentHeadroom(t *testing.T) {
+	transport := newRateLimitTransport(nil, 10, 10)
+
+	header := http.Header{}
+	header.Set("RateLimit-Limit", "100")
+	header.Set("RateLimit-Remaining", "5") // 5% headroom, below the 10% threshold
+	transport.adjustFromHeaders(header)
+
+	if got, want := transport.limiter.Limit(), rate.Limit(5); got != want {
+		t.Fatalf("Limit() = %v, want %v (halved from 10)", got, want)
+	}
+}
+
+func TestAdjustFromHeadersLeavesRateAloneAboveThreshold(t *testing.T) {
+	transport := newRateLimitTransport(nil, 10, 10)
+
+	header := http.Header{}
+	header.Set("RateLimit-Limit", "100")
+	header.Set("RateLimit-Remaining", "50") // 50% headroom, well above the threshold
+	transport.adjustFromHeaders(header)
+
+	if got, want := transport.limiter.Limit(), rate.Limit(10); got != want {
+		t.Fatalf("Limit() = %v, want %v (unchanged)", got, want)
+	}
+}
+
+func TestAdjustFromHeadersNeverRaisesRateBackUp(t *testing.T) {
+	transport := newRateLimitTransport(nil, 10, 10)
+
+	low := http.Header{}
+	low.Set("RateLimit-Limit", "100")
+	low.Set("RateLimit-Remaining", "5")
+	transport.adjustFromHeaders(low)
+	if got := transport.limiter.Limit(); got != 5 {
+		t.Fatalf("after low headroom, Limit() = %v, want 5", got)
+	}
+
+	// Headroom recovering (e.g. the quota window reset) should not undo
+	// the earlier back-off.
+	high := http.Header{}
+	high.Set("RateLimit-Limit", "100")
+	high.Set("RateLimit-Remaining", "99")
+	transport.adjustFromHeaders(high)
+	if got := transport.limiter.Limit(); got != 5 {
+		t.Fatalf("after high headroom, Limit() = %v, want still 5", got)
+	}
+}
+
+func TestAdjustFromHeadersIgnoresMissingOrZeroLimit(t *testing.T) {
+	transport := newRateLimitTransport(nil, 10, 10)
+
+	transport.adjustFromHeaders(http.Header{}) // no headers at all
+	if got := transport.limiter.Limit(); got != 10 {
+		t.Fatalf("with no headers, Limit() = %v, want unchanged 10", got)
+	}
+
+	header := http.Header{}
+	header.Set("RateLimit-Limit", "0")
+	header.Set("RateLimit-Remaining", "0")
+	transport.adjustFromHeaders(header)
+	if got := transport.limiter.Limit(); got != 10 {
+		t.Fatalf("with RateLimit-Limit=0, Limit() = %v, want unchanged 10", got)
+	}
+}
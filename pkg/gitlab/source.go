@@ -0,0 +1,25 @@
+package gitlab
+
+// Source abstracts "somewhere projects live" so Fetcher's callers can
+// resolve project/group URLs without caring which forge they came from.
+// GitLabSource is the only implementation today; GitHubSource/GiteaSource
+// can implement the same interface once this tool grows beyond GitLab.
+type Source interface {
+	ResolveProjects(urls []string, includeGlob, excludeGlob string) ([]*Project, error)
+}
+
+// GitLabSource resolves project/group URLs against a single GitLab instance.
+type GitLabSource struct {
+	client  *Client
+	baseURL string
+}
+
+// NewGitLabSource builds a Source backed by client, whose URLs are expected
+// to point at baseURL.
+func NewGitLabSource(client *Client, baseURL string) *GitLabSource {
+	return &GitLabSource{client: client, baseURL: baseURL}
+}
+
+func (s *GitLabSource) ResolveProjects(urls []string, includeGlob, excludeGlob string) ([]*Project, error) {
+	return resolveProjects(s.client, s.baseURL, urls, includeGlob, excludeGlob)
+}
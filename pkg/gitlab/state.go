@@ -0,0 +1,49 @@
+package gitlab
+
+import (
+	"fmt"
+	"log"
+	"os"
+)
+
+// LoadFetchedProjects reads the list of already fetched project IDs from
+// dataDir's index, for skipping them on a "resume" run.
+func LoadFetchedProjects(dataDir string) map[int64]bool {
+	fetched := make(map[int64]bool)
+	indexFile := fmt.Sprintf("%s/.fetched_projects", dataDir)
+
+	file, err := os.Open(indexFile)
+	if err != nil {
+		return fetched
+	}
+	defer file.Close()
+
+	var projectID int64
+	var projectPath string
+	for {
+		_, err := fmt.Fscanf(file, "%d %s\n", &projectID, &projectPath)
+		if err != nil {
+			break
+		}
+		fetched[projectID] = true
+	}
+
+	return fetched
+}
+
+// MarkProjectFetched adds a project to the fetched projects index.
+func MarkProjectFetched(dataDir string, projectID int64, projectPath string) {
+	indexFile := fmt.Sprintf("%s/.fetched_projects", dataDir)
+
+	file, err := os.OpenFile(indexFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		log.Printf("Error marking project as fetched: %v", err)
+		return
+	}
+	defer file.Close()
+
+	_, err = fmt.Fprintf(file, "%d %s\n", projectID, projectPath)
+	if err != nil {
+		log.Fatalf("Error marking project as fetched: %v", err)
+	}
+}
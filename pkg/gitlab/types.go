@@ -0,0 +1,42 @@
+// Package gitlab wraps gitlab.com/gitlab-org/api/client-go with the
+// fetch-with-worker-pool, HTTP caching and rate-limiting behavior this tool
+// needs, behind a Fetcher/Source pair that doesn't leak storage or CLI
+// concerns into the GitLab-specific code.
+package gitlab
+
+import (
+	"time"
+
+	glapi "gitlab.com/gitlab-org/api/client-go"
+)
+
+// Client, Project and the entity types are aliased rather than redefined so
+// callers can pass values straight through to pkg/sink without conversion,
+// while still depending only on this package's import path.
+type (
+	Client            = glapi.Client
+	Project           = glapi.Project
+	Commit            = glapi.Commit
+	CommitStats       = glapi.CommitStats
+	BasicMergeRequest = glapi.BasicMergeRequest
+	BasicUser         = glapi.BasicUser
+	Note              = glapi.Note
+	NoteAuthor        = glapi.NoteAuthor
+	Pipeline          = glapi.PipelineInfo
+	Issue             = glapi.Issue
+)
+
+// FormatDate renders date in the layout the sinks and CSV export expect, or
+// "" if date is nil.
+func FormatDate(date *time.Time) string {
+	if date == nil {
+		return ""
+	}
+	return date.Format(time.RFC3339)
+}
+
+// Ptr returns a pointer to a copy of v, for building API options that take
+// pointers to literals (e.g. gl.Ptr(time.Now())).
+func Ptr[T any](v T) *T {
+	return &v
+}
@@ -0,0 +1,624 @@
+package sink
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	gl "github.com/AlexeyBelezeko/GitlabStatFetcher/pkg/gitlab"
+	glapi "gitlab.com/gitlab-org/api/client-go"
+)
+
+// csvSink is the original output format: one append-only CSV file per
+// entity under dataDir. Each WriteX call opens, appends and closes the
+// file so partial output survives an interrupted run.
+type csvSink struct {
+	dataDir string
+}
+
+func newCSVSink(dataDir string) *csvSink {
+	return &csvSink{dataDir: dataDir}
+}
+
+func (s *csvSink) WriteCommits(commits []*gl.Commit) error {
+	return writeCommitsCSV(commits, fmt.Sprintf("%s/commits.csv", s.dataDir))
+}
+
+func (s *csvSink) WriteMRs(mrs []*gl.BasicMergeRequest) error {
+	return writeMRsCSV(mrs, fmt.Sprintf("%s/merge_requests.csv", s.dataDir))
+}
+
+func (s *csvSink) WriteNotes(notes []*gl.Note) error {
+	if len(notes) == 0 {
+		return nil
+	}
+	return writeNotesCSV(notes, fmt.Sprintf("%s/notes.csv", s.dataDir))
+}
+
+func (s *csvSink) WritePipelines(pipelines []*gl.Pipeline) error {
+	if len(pipelines) == 0 {
+		return nil
+	}
+	return writePipelinesCSV(pipelines, fmt.Sprintf("%s/pipelines.csv", s.dataDir))
+}
+
+func (s *csvSink) WriteIssues(issues []*gl.Issue) error {
+	if len(issues) == 0 {
+		return nil
+	}
+	return writeIssuesCSV(issues, fmt.Sprintf("%s/issues.csv", s.dataDir))
+}
+
+func (s *csvSink) WriteMRApprovals(approvals []*gl.MRApproval) error {
+	if len(approvals) == 0 {
+		return nil
+	}
+	return writeMRApprovalsCSV(approvals, fmt.Sprintf("%s/mr_approvals.csv", s.dataDir))
+}
+
+func (s *csvSink) WriteMRCommits(mrCommits []*gl.MRCommit) error {
+	if len(mrCommits) == 0 {
+		return nil
+	}
+	return writeMRCommitsCSV(mrCommits, fmt.Sprintf("%s/mr_commits.csv", s.dataDir))
+}
+
+func (s *csvSink) Flush() error { return nil }
+
+func (s *csvSink) Close() error { return nil }
+
+// Write CSV files (append mode for consolidated output)
+func writeCommitsCSV(commits []*gl.Commit, filename string) error {
+	file, err := os.OpenFile(filename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	stat, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("could not stat file: %v", err)
+	}
+	hasHeader := stat.Size() > 0
+
+	w := csv.NewWriter(file)
+	defer w.Flush()
+
+	if !hasHeader {
+		w.Write([]string{"project_id", "id", "author_name", "author_email", "date", "message",
+			"additions", "deletions", "total"})
+	}
+
+	// Rows
+	for _, c := range commits {
+		// Clean message: replace newlines with spaces for better CSV compatibility
+		cleanMessage := strings.ReplaceAll(c.Message, "\n", " ")
+		cleanMessage = strings.ReplaceAll(cleanMessage, "\r", " ")
+
+		w.Write([]string{
+			strconv.FormatInt(c.ProjectID, 10),
+			c.ID,
+			c.AuthorName,
+			c.AuthorEmail,
+			gl.FormatDate(c.CommittedDate),
+			cleanMessage,
+			strconv.FormatInt(c.Stats.Additions, 10),
+			strconv.FormatInt(c.Stats.Deletions, 10),
+			strconv.FormatInt(c.Stats.Total, 10),
+		})
+	}
+
+	return nil
+}
+
+func writeMRsCSV(mrs []*gl.BasicMergeRequest, filename string) error {
+	file, err := os.OpenFile(filename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	stat, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("could not stat file: %v", err)
+	}
+	hasHeader := stat.Size() > 0
+
+	w := csv.NewWriter(file)
+	defer w.Flush()
+
+	if !hasHeader {
+		w.Write([]string{"project_id", "mr_id", "title", "state", "author_username", "author_name",
+			"created_at", "merged_at", "source_branch", "target_branch",
+			"sha", "merge_commit_sha", "squash_commit_sha"})
+	}
+
+	for _, m := range mrs {
+		w.Write([]string{
+			strconv.FormatInt(m.ProjectID, 10),
+			strconv.FormatInt(m.ID, 10),
+			m.Title,
+			m.State,
+			m.Author.Username,
+			m.Author.Name,
+			gl.FormatDate(m.CreatedAt),
+			gl.FormatDate(m.MergedAt),
+			m.SourceBranch,
+			m.TargetBranch,
+			m.SHA,
+			m.MergeCommitSHA,
+			m.SquashCommitSHA,
+		})
+	}
+
+	return nil
+}
+
+func writeNotesCSV(notes []*gl.Note, filename string) error {
+	file, err := os.OpenFile(filename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	stat, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("could not stat file: %v", err)
+	}
+	hasHeader := stat.Size() > 0
+
+	w := csv.NewWriter(file)
+	defer w.Flush()
+
+	if !hasHeader {
+		w.Write([]string{"project_id", "note_id", "author_name", "author_username",
+			"created_at", "updated_at", "body", "system"})
+	}
+
+	// Rows
+	for _, n := range notes {
+		// Clean body: replace newlines for better CSV compatibility
+		cleanBody := strings.ReplaceAll(n.Body, "\n", " ")
+		cleanBody = strings.ReplaceAll(cleanBody, "\r", " ")
+
+		w.Write([]string{
+			strconv.FormatInt(n.ProjectID, 10),
+			strconv.FormatInt(n.ID, 10),
+			n.Author.Name,
+			n.Author.Username,
+			gl.FormatDate(n.CreatedAt),
+			gl.FormatDate(n.UpdatedAt),
+			cleanBody,
+			strconv.FormatBool(n.System),
+		})
+	}
+
+	return nil
+}
+
+func writePipelinesCSV(pipelines []*gl.Pipeline, filename string) error {
+	file, err := os.OpenFile(filename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	stat, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("could not stat file: %v", err)
+	}
+	hasHeader := stat.Size() > 0
+
+	w := csv.NewWriter(file)
+	defer w.Flush()
+
+	if !hasHeader {
+		w.Write([]string{"project_id", "pipeline_id", "status", "ref", "sha", "created_at", "updated_at"})
+	}
+
+	for _, p := range pipelines {
+		w.Write([]string{
+			strconv.FormatInt(p.ProjectID, 10),
+			strconv.FormatInt(p.ID, 10),
+			p.Status,
+			p.Ref,
+			p.SHA,
+			gl.FormatDate(p.CreatedAt),
+			gl.FormatDate(p.UpdatedAt),
+		})
+	}
+
+	return nil
+}
+
+func writeIssuesCSV(issues []*gl.Issue, filename string) error {
+	file, err := os.OpenFile(filename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	stat, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("could not stat file: %v", err)
+	}
+	hasHeader := stat.Size() > 0
+
+	w := csv.NewWriter(file)
+	defer w.Flush()
+
+	if !hasHeader {
+		w.Write([]string{"project_id", "issue_id", "title", "state", "author_username", "author_name",
+			"created_at", "updated_at", "closed_at"})
+	}
+
+	for _, i := range issues {
+		w.Write([]string{
+			strconv.FormatInt(i.ProjectID, 10),
+			strconv.FormatInt(i.ID, 10),
+			i.Title,
+			i.State,
+			i.Author.Username,
+			i.Author.Name,
+			gl.FormatDate(i.CreatedAt),
+			gl.FormatDate(i.UpdatedAt),
+			gl.FormatDate(i.ClosedAt),
+		})
+	}
+
+	return nil
+}
+
+func writeMRApprovalsCSV(approvals []*gl.MRApproval, filename string) error {
+	file, err := os.OpenFile(filename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	stat, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("could not stat file: %v", err)
+	}
+	hasHeader := stat.Size() > 0
+
+	w := csv.NewWriter(file)
+	defer w.Flush()
+
+	if !hasHeader {
+		w.Write([]string{"project_id", "mr_iid", "approvals_required", "approvals_left", "approved", "approved_by"})
+	}
+
+	for _, a := range approvals {
+		w.Write([]string{
+			strconv.FormatInt(a.ProjectID, 10),
+			strconv.FormatInt(a.MRIID, 10),
+			strconv.Itoa(a.ApprovalsRequired),
+			strconv.Itoa(a.ApprovalsLeft),
+			strconv.FormatBool(a.Approved),
+			strings.Join(a.ApprovedBy, ";"),
+		})
+	}
+
+	return nil
+}
+
+func writeMRCommitsCSV(mrCommits []*gl.MRCommit, filename string) error {
+	file, err := os.OpenFile(filename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	stat, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("could not stat file: %v", err)
+	}
+	hasHeader := stat.Size() > 0
+
+	w := csv.NewWriter(file)
+	defer w.Flush()
+
+	if !hasHeader {
+		w.Write([]string{"project_id", "mr_iid", "commit_id"})
+	}
+
+	for _, c := range mrCommits {
+		w.Write([]string{
+			strconv.FormatInt(c.ProjectID, 10),
+			strconv.FormatInt(c.MRIID, 10),
+			c.CommitID,
+		})
+	}
+
+	return nil
+}
+
+// CSVExport holds every entity ReadCSV reconstructed from a data
+// directory's CSV output, for the "export" command that converts it to
+// another sink format. A nil slice means that entity's CSV file didn't
+// exist (it was skipped at fetch time, e.g. via --skip-pipelines).
+type CSVExport struct {
+	Commits     []*gl.Commit
+	MRs         []*gl.BasicMergeRequest
+	Notes       []*gl.Note
+	Pipelines   []*gl.Pipeline
+	Issues      []*gl.Issue
+	MRApprovals []*gl.MRApproval
+	MRCommits   []*gl.MRCommit
+}
+
+// ReadCSV reconstructs the GitLab API types from a data directory's CSV
+// output, for the "export" command that converts existing CSV output to
+// another sink format.
+func ReadCSV(dataDir string) (*CSVExport, error) {
+	commits, err := readCommitsCSV(fmt.Sprintf("%s/commits.csv", dataDir))
+	if err != nil {
+		return nil, fmt.Errorf("reading commits.csv: %v", err)
+	}
+	mrs, err := readMRsCSV(fmt.Sprintf("%s/merge_requests.csv", dataDir))
+	if err != nil {
+		return nil, fmt.Errorf("reading merge_requests.csv: %v", err)
+	}
+	notes, err := readNotesCSV(fmt.Sprintf("%s/notes.csv", dataDir))
+	if err != nil {
+		return nil, fmt.Errorf("reading notes.csv: %v", err)
+	}
+	pipelines, err := readPipelinesCSV(fmt.Sprintf("%s/pipelines.csv", dataDir))
+	if err != nil {
+		return nil, fmt.Errorf("reading pipelines.csv: %v", err)
+	}
+	issues, err := readIssuesCSV(fmt.Sprintf("%s/issues.csv", dataDir))
+	if err != nil {
+		return nil, fmt.Errorf("reading issues.csv: %v", err)
+	}
+	mrApprovals, err := readMRApprovalsCSV(fmt.Sprintf("%s/mr_approvals.csv", dataDir))
+	if err != nil {
+		return nil, fmt.Errorf("reading mr_approvals.csv: %v", err)
+	}
+	mrCommits, err := readMRCommitsCSV(fmt.Sprintf("%s/mr_commits.csv", dataDir))
+	if err != nil {
+		return nil, fmt.Errorf("reading mr_commits.csv: %v", err)
+	}
+	return &CSVExport{
+		Commits:     commits,
+		MRs:         mrs,
+		Notes:       notes,
+		Pipelines:   pipelines,
+		Issues:      issues,
+		MRApprovals: mrApprovals,
+		MRCommits:   mrCommits,
+	}, nil
+}
+
+// readCommitsCSV and the readXCSV functions below it reconstruct the
+// GitLab API types from previously written CSV output. A missing file is
+// not an error: it just means that entity wasn't fetched.
+func readCommitsCSV(filename string) ([]*gl.Commit, error) {
+	rows, err := readCSVRows(filename)
+	if err != nil || rows == nil {
+		return nil, err
+	}
+
+	commits := make([]*gl.Commit, 0, len(rows))
+	for _, row := range rows {
+		projectID, _ := strconv.ParseInt(row[0], 10, 64)
+		additions, _ := strconv.ParseInt(row[6], 10, 64)
+		deletions, _ := strconv.ParseInt(row[7], 10, 64)
+		total, _ := strconv.ParseInt(row[8], 10, 64)
+
+		commits = append(commits, &gl.Commit{
+			ProjectID:     projectID,
+			ID:            row[1],
+			AuthorName:    row[2],
+			AuthorEmail:   row[3],
+			CommittedDate: parseOptionalDate(row[4]),
+			Message:       row[5],
+			Stats:         &gl.CommitStats{Additions: additions, Deletions: deletions, Total: total},
+		})
+	}
+	return commits, nil
+}
+
+func readMRsCSV(filename string) ([]*gl.BasicMergeRequest, error) {
+	rows, err := readCSVRows(filename)
+	if err != nil || rows == nil {
+		return nil, err
+	}
+
+	mrs := make([]*gl.BasicMergeRequest, 0, len(rows))
+	for _, row := range rows {
+		projectID, _ := strconv.ParseInt(row[0], 10, 64)
+		id, _ := strconv.ParseInt(row[1], 10, 64)
+
+		mrs = append(mrs, &gl.BasicMergeRequest{
+			ProjectID:       projectID,
+			ID:              id,
+			Title:           row[2],
+			State:           row[3],
+			Author:          &gl.BasicUser{Username: row[4], Name: row[5]},
+			CreatedAt:       parseOptionalDate(row[6]),
+			MergedAt:        parseOptionalDate(row[7]),
+			SourceBranch:    row[8],
+			TargetBranch:    row[9],
+			SHA:             row[10],
+			MergeCommitSHA:  row[11],
+			SquashCommitSHA: row[12],
+		})
+	}
+	return mrs, nil
+}
+
+func readNotesCSV(filename string) ([]*gl.Note, error) {
+	rows, err := readCSVRows(filename)
+	if err != nil || rows == nil {
+		return nil, err
+	}
+
+	notes := make([]*gl.Note, 0, len(rows))
+	for _, row := range rows {
+		projectID, _ := strconv.ParseInt(row[0], 10, 64)
+		id, _ := strconv.ParseInt(row[1], 10, 64)
+		system, _ := strconv.ParseBool(row[7])
+
+		notes = append(notes, &gl.Note{
+			ProjectID: projectID,
+			ID:        id,
+			Author:    gl.NoteAuthor{Name: row[2], Username: row[3]},
+			CreatedAt: parseOptionalDate(row[4]),
+			UpdatedAt: parseOptionalDate(row[5]),
+			Body:      row[6],
+			System:    system,
+		})
+	}
+	return notes, nil
+}
+
+func readPipelinesCSV(filename string) ([]*gl.Pipeline, error) {
+	rows, err := readCSVRows(filename)
+	if err != nil || rows == nil {
+		return nil, err
+	}
+
+	pipelines := make([]*gl.Pipeline, 0, len(rows))
+	for _, row := range rows {
+		projectID, _ := strconv.ParseInt(row[0], 10, 64)
+		id, _ := strconv.ParseInt(row[1], 10, 64)
+
+		pipelines = append(pipelines, &gl.Pipeline{
+			ProjectID: projectID,
+			ID:        id,
+			Status:    row[2],
+			Ref:       row[3],
+			SHA:       row[4],
+			CreatedAt: parseOptionalDate(row[5]),
+			UpdatedAt: parseOptionalDate(row[6]),
+		})
+	}
+	return pipelines, nil
+}
+
+func readIssuesCSV(filename string) ([]*gl.Issue, error) {
+	rows, err := readCSVRows(filename)
+	if err != nil || rows == nil {
+		return nil, err
+	}
+
+	issues := make([]*gl.Issue, 0, len(rows))
+	for _, row := range rows {
+		projectID, _ := strconv.ParseInt(row[0], 10, 64)
+		id, _ := strconv.ParseInt(row[1], 10, 64)
+
+		issues = append(issues, &gl.Issue{
+			ProjectID: projectID,
+			ID:        id,
+			Title:     row[2],
+			State:     row[3],
+			Author:    &glapi.IssueAuthor{Username: row[4], Name: row[5]},
+			CreatedAt: parseOptionalDate(row[6]),
+			UpdatedAt: parseOptionalDate(row[7]),
+			ClosedAt:  parseOptionalDate(row[8]),
+		})
+	}
+	return issues, nil
+}
+
+func readMRApprovalsCSV(filename string) ([]*gl.MRApproval, error) {
+	rows, err := readCSVRows(filename)
+	if err != nil || rows == nil {
+		return nil, err
+	}
+
+	approvals := make([]*gl.MRApproval, 0, len(rows))
+	for _, row := range rows {
+		projectID, _ := strconv.ParseInt(row[0], 10, 64)
+		mrIID, _ := strconv.ParseInt(row[1], 10, 64)
+		approvalsRequired, _ := strconv.Atoi(row[2])
+		approvalsLeft, _ := strconv.Atoi(row[3])
+		approved, _ := strconv.ParseBool(row[4])
+
+		var approvedBy []string
+		if row[5] != "" {
+			approvedBy = strings.Split(row[5], ";")
+		}
+
+		approvals = append(approvals, &gl.MRApproval{
+			ProjectID:         projectID,
+			MRIID:             mrIID,
+			ApprovalsRequired: approvalsRequired,
+			ApprovalsLeft:     approvalsLeft,
+			Approved:          approved,
+			ApprovedBy:        approvedBy,
+		})
+	}
+	return approvals, nil
+}
+
+func readMRCommitsCSV(filename string) ([]*gl.MRCommit, error) {
+	rows, err := readCSVRows(filename)
+	if err != nil || rows == nil {
+		return nil, err
+	}
+
+	mrCommits := make([]*gl.MRCommit, 0, len(rows))
+	for _, row := range rows {
+		projectID, _ := strconv.ParseInt(row[0], 10, 64)
+		mrIID, _ := strconv.ParseInt(row[1], 10, 64)
+
+		mrCommits = append(mrCommits, &gl.MRCommit{
+			ProjectID: projectID,
+			MRIID:     mrIID,
+			CommitID:  row[2],
+		})
+	}
+	return mrCommits, nil
+}
+
+// readCSVRows returns the data rows of filename (header skipped), nil rows
+// and nil error if the file does not exist.
+func readCSVRows(filename string) ([][]string, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer file.Close()
+
+	r := csv.NewReader(file)
+	if _, err := r.Read(); err != nil { // header
+		if err == io.EOF {
+			return [][]string{}, nil
+		}
+		return nil, err
+	}
+
+	rows := [][]string{}
+	for {
+		row, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func parseOptionalDate(value string) *time.Time {
+	if value == "" {
+		return nil
+	}
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil
+	}
+	return &parsed
+}
@@ -0,0 +1,82 @@
+package sink
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	gl "github.com/AlexeyBelezeko/GitlabStatFetcher/pkg/gitlab"
+)
+
+// jsonlSink writes one JSON object per line per entity, append-only like
+// the csv sink, for pipelines that would rather not parse CSV.
+type jsonlSink struct {
+	dataDir string
+}
+
+func newJSONLSink(dataDir string) *jsonlSink {
+	return &jsonlSink{dataDir: dataDir}
+}
+
+func (s *jsonlSink) WriteCommits(commits []*gl.Commit) error {
+	return appendJSONL(fmt.Sprintf("%s/commits.jsonl", s.dataDir), commits)
+}
+
+func (s *jsonlSink) WriteMRs(mrs []*gl.BasicMergeRequest) error {
+	return appendJSONL(fmt.Sprintf("%s/merge_requests.jsonl", s.dataDir), mrs)
+}
+
+func (s *jsonlSink) WriteNotes(notes []*gl.Note) error {
+	if len(notes) == 0 {
+		return nil
+	}
+	return appendJSONL(fmt.Sprintf("%s/notes.jsonl", s.dataDir), notes)
+}
+
+func (s *jsonlSink) WritePipelines(pipelines []*gl.Pipeline) error {
+	if len(pipelines) == 0 {
+		return nil
+	}
+	return appendJSONL(fmt.Sprintf("%s/pipelines.jsonl", s.dataDir), pipelines)
+}
+
+func (s *jsonlSink) WriteIssues(issues []*gl.Issue) error {
+	if len(issues) == 0 {
+		return nil
+	}
+	return appendJSONL(fmt.Sprintf("%s/issues.jsonl", s.dataDir), issues)
+}
+
+func (s *jsonlSink) WriteMRApprovals(approvals []*gl.MRApproval) error {
+	if len(approvals) == 0 {
+		return nil
+	}
+	return appendJSONL(fmt.Sprintf("%s/mr_approvals.jsonl", s.dataDir), approvals)
+}
+
+func (s *jsonlSink) WriteMRCommits(mrCommits []*gl.MRCommit) error {
+	if len(mrCommits) == 0 {
+		return nil
+	}
+	return appendJSONL(fmt.Sprintf("%s/mr_commits.jsonl", s.dataDir), mrCommits)
+}
+
+func (s *jsonlSink) Flush() error { return nil }
+
+func (s *jsonlSink) Close() error { return nil }
+
+func appendJSONL[T any](filename string, rows []T) error {
+	file, err := os.OpenFile(filename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	enc := json.NewEncoder(file)
+	for _, row := range rows {
+		if err := enc.Encode(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,328 @@
+package sink
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/parquet-go/parquet-go"
+
+	gl "github.com/AlexeyBelezeko/GitlabStatFetcher/pkg/gitlab"
+)
+
+// parquetSink writes one columnar Parquet file per entity under dataDir,
+// for efficient analytics queries on large orgs. parquet-go needs the full
+// row set up front, so each WriteX call buffers its rows in memory; Flush
+// (called once per project) reads back whatever rows already exist on
+// disk, appends the buffered ones and rewrites the file once per entity.
+type parquetSink struct {
+	dataDir string
+
+	mu          sync.Mutex
+	commits     []commitRow
+	mrs         []mrRow
+	notes       []noteRow
+	pipelines   []pipelineRow
+	issues      []issueRow
+	mrApprovals []mrApprovalRow
+	mrCommits   []mrCommitRow
+}
+
+func newParquetSink(dataDir string) (*parquetSink, error) {
+	return &parquetSink{dataDir: dataDir}, nil
+}
+
+type commitRow struct {
+	ProjectID   int64  `parquet:"project_id"`
+	ID          string `parquet:"id"`
+	AuthorName  string `parquet:"author_name"`
+	AuthorEmail string `parquet:"author_email"`
+	Date        string `parquet:"date"`
+	Message     string `parquet:"message"`
+	Additions   int64  `parquet:"additions"`
+	Deletions   int64  `parquet:"deletions"`
+	Total       int64  `parquet:"total"`
+}
+
+type mrRow struct {
+	ProjectID       int64  `parquet:"project_id"`
+	ID              int64  `parquet:"mr_id"`
+	Title           string `parquet:"title"`
+	State           string `parquet:"state"`
+	AuthorUsername  string `parquet:"author_username"`
+	AuthorName      string `parquet:"author_name"`
+	CreatedAt       string `parquet:"created_at"`
+	MergedAt        string `parquet:"merged_at"`
+	SourceBranch    string `parquet:"source_branch"`
+	TargetBranch    string `parquet:"target_branch"`
+	SHA             string `parquet:"sha"`
+	MergeCommitSHA  string `parquet:"merge_commit_sha"`
+	SquashCommitSHA string `parquet:"squash_commit_sha"`
+}
+
+type noteRow struct {
+	ProjectID      int64  `parquet:"project_id"`
+	ID             int64  `parquet:"note_id"`
+	AuthorName     string `parquet:"author_name"`
+	AuthorUsername string `parquet:"author_username"`
+	CreatedAt      string `parquet:"created_at"`
+	UpdatedAt      string `parquet:"updated_at"`
+	Body           string `parquet:"body"`
+	System         bool   `parquet:"system"`
+}
+
+type pipelineRow struct {
+	ProjectID int64  `parquet:"project_id"`
+	ID        int64  `parquet:"pipeline_id"`
+	Status    string `parquet:"status"`
+	Ref       string `parquet:"ref"`
+	SHA       string `parquet:"sha"`
+	CreatedAt string `parquet:"created_at"`
+	UpdatedAt string `parquet:"updated_at"`
+}
+
+type issueRow struct {
+	ProjectID      int64  `parquet:"project_id"`
+	ID             int64  `parquet:"issue_id"`
+	Title          string `parquet:"title"`
+	State          string `parquet:"state"`
+	AuthorUsername string `parquet:"author_username"`
+	AuthorName     string `parquet:"author_name"`
+	CreatedAt      string `parquet:"created_at"`
+	UpdatedAt      string `parquet:"updated_at"`
+	ClosedAt       string `parquet:"closed_at"`
+}
+
+type mrApprovalRow struct {
+	ProjectID         int64  `parquet:"project_id"`
+	MRIID             int64  `parquet:"mr_iid"`
+	ApprovalsRequired int    `parquet:"approvals_required"`
+	ApprovalsLeft     int    `parquet:"approvals_left"`
+	Approved          bool   `parquet:"approved"`
+	ApprovedBy        string `parquet:"approved_by"`
+}
+
+type mrCommitRow struct {
+	ProjectID int64  `parquet:"project_id"`
+	MRIID     int64  `parquet:"mr_iid"`
+	CommitID  string `parquet:"commit_id"`
+}
+
+func (s *parquetSink) WriteCommits(commits []*gl.Commit) error {
+	rows := make([]commitRow, len(commits))
+	for i, c := range commits {
+		rows[i] = commitRow{
+			ProjectID:   c.ProjectID,
+			ID:          c.ID,
+			AuthorName:  c.AuthorName,
+			AuthorEmail: c.AuthorEmail,
+			Date:        gl.FormatDate(c.CommittedDate),
+			Message:     c.Message,
+			Additions:   c.Stats.Additions,
+			Deletions:   c.Stats.Deletions,
+			Total:       c.Stats.Total,
+		}
+	}
+	s.mu.Lock()
+	s.commits = append(s.commits, rows...)
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *parquetSink) WriteMRs(mrs []*gl.BasicMergeRequest) error {
+	rows := make([]mrRow, len(mrs))
+	for i, m := range mrs {
+		rows[i] = mrRow{
+			ProjectID:       m.ProjectID,
+			ID:              m.ID,
+			Title:           m.Title,
+			State:           m.State,
+			AuthorUsername:  m.Author.Username,
+			AuthorName:      m.Author.Name,
+			CreatedAt:       gl.FormatDate(m.CreatedAt),
+			MergedAt:        gl.FormatDate(m.MergedAt),
+			SourceBranch:    m.SourceBranch,
+			TargetBranch:    m.TargetBranch,
+			SHA:             m.SHA,
+			MergeCommitSHA:  m.MergeCommitSHA,
+			SquashCommitSHA: m.SquashCommitSHA,
+		}
+	}
+	s.mu.Lock()
+	s.mrs = append(s.mrs, rows...)
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *parquetSink) WriteNotes(notes []*gl.Note) error {
+	if len(notes) == 0 {
+		return nil
+	}
+	rows := make([]noteRow, len(notes))
+	for i, n := range notes {
+		rows[i] = noteRow{
+			ProjectID:      n.ProjectID,
+			ID:             n.ID,
+			AuthorName:     n.Author.Name,
+			AuthorUsername: n.Author.Username,
+			CreatedAt:      gl.FormatDate(n.CreatedAt),
+			UpdatedAt:      gl.FormatDate(n.UpdatedAt),
+			Body:           n.Body,
+			System:         n.System,
+		}
+	}
+	s.mu.Lock()
+	s.notes = append(s.notes, rows...)
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *parquetSink) WritePipelines(pipelines []*gl.Pipeline) error {
+	if len(pipelines) == 0 {
+		return nil
+	}
+	rows := make([]pipelineRow, len(pipelines))
+	for i, p := range pipelines {
+		rows[i] = pipelineRow{
+			ProjectID: p.ProjectID,
+			ID:        p.ID,
+			Status:    p.Status,
+			Ref:       p.Ref,
+			SHA:       p.SHA,
+			CreatedAt: gl.FormatDate(p.CreatedAt),
+			UpdatedAt: gl.FormatDate(p.UpdatedAt),
+		}
+	}
+	s.mu.Lock()
+	s.pipelines = append(s.pipelines, rows...)
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *parquetSink) WriteIssues(issues []*gl.Issue) error {
+	if len(issues) == 0 {
+		return nil
+	}
+	rows := make([]issueRow, len(issues))
+	for i, iss := range issues {
+		rows[i] = issueRow{
+			ProjectID:      iss.ProjectID,
+			ID:             iss.ID,
+			Title:          iss.Title,
+			State:          iss.State,
+			AuthorUsername: iss.Author.Username,
+			AuthorName:     iss.Author.Name,
+			CreatedAt:      gl.FormatDate(iss.CreatedAt),
+			UpdatedAt:      gl.FormatDate(iss.UpdatedAt),
+			ClosedAt:       gl.FormatDate(iss.ClosedAt),
+		}
+	}
+	s.mu.Lock()
+	s.issues = append(s.issues, rows...)
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *parquetSink) WriteMRApprovals(approvals []*gl.MRApproval) error {
+	if len(approvals) == 0 {
+		return nil
+	}
+	rows := make([]mrApprovalRow, len(approvals))
+	for i, a := range approvals {
+		rows[i] = mrApprovalRow{
+			ProjectID:         a.ProjectID,
+			MRIID:             a.MRIID,
+			ApprovalsRequired: a.ApprovalsRequired,
+			ApprovalsLeft:     a.ApprovalsLeft,
+			Approved:          a.Approved,
+			ApprovedBy:        strings.Join(a.ApprovedBy, ";"),
+		}
+	}
+	s.mu.Lock()
+	s.mrApprovals = append(s.mrApprovals, rows...)
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *parquetSink) WriteMRCommits(mrCommits []*gl.MRCommit) error {
+	if len(mrCommits) == 0 {
+		return nil
+	}
+	rows := make([]mrCommitRow, len(mrCommits))
+	for i, c := range mrCommits {
+		rows[i] = mrCommitRow{ProjectID: c.ProjectID, MRIID: c.MRIID, CommitID: c.CommitID}
+	}
+	s.mu.Lock()
+	s.mrCommits = append(s.mrCommits, rows...)
+	s.mu.Unlock()
+	return nil
+}
+
+// Flush writes each entity's rows buffered since the last Flush to its
+// Parquet file, reading back whatever already exists on disk exactly once
+// per entity instead of on every WriteX call, then clears the buffers so
+// the caller (one Flush per project) never holds more than one project's
+// rows in memory at a time.
+func (s *parquetSink) Flush() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := flushParquet(fmt.Sprintf("%s/commits.parquet", s.dataDir), s.commits); err != nil {
+		return err
+	}
+	if err := flushParquet(fmt.Sprintf("%s/merge_requests.parquet", s.dataDir), s.mrs); err != nil {
+		return err
+	}
+	if err := flushParquet(fmt.Sprintf("%s/notes.parquet", s.dataDir), s.notes); err != nil {
+		return err
+	}
+	if err := flushParquet(fmt.Sprintf("%s/pipelines.parquet", s.dataDir), s.pipelines); err != nil {
+		return err
+	}
+	if err := flushParquet(fmt.Sprintf("%s/issues.parquet", s.dataDir), s.issues); err != nil {
+		return err
+	}
+	if err := flushParquet(fmt.Sprintf("%s/mr_approvals.parquet", s.dataDir), s.mrApprovals); err != nil {
+		return err
+	}
+	if err := flushParquet(fmt.Sprintf("%s/mr_commits.parquet", s.dataDir), s.mrCommits); err != nil {
+		return err
+	}
+
+	s.commits, s.mrs, s.notes, s.pipelines, s.issues, s.mrApprovals, s.mrCommits = nil, nil, nil, nil, nil, nil, nil
+	return nil
+}
+
+// Close flushes any rows buffered since the last per-project Flush, so a
+// run that's interrupted between fetchAll and its Flush call doesn't lose
+// the project's output entirely.
+func (s *parquetSink) Close() error {
+	return s.Flush()
+}
+
+// flushParquet appends rows to filename's existing contents (if any) and
+// rewrites it in one shot. A nil/empty rows means this entity got no rows
+// since the last Flush, so the existing file (if any) is left untouched.
+func flushParquet[T any](filename string, rows []T) error {
+	if len(rows) == 0 {
+		return nil
+	}
+
+	var existing []T
+	if f, err := os.Open(filename); err == nil {
+		stat, statErr := f.Stat()
+		if statErr == nil {
+			existing, _ = parquet.Read[T](f, stat.Size())
+		}
+		f.Close()
+	}
+
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return parquet.Write(f, append(existing, rows...))
+}
@@ -0,0 +1,275 @@
+package sink
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	_ "github.com/lib/pq"
+	_ "modernc.org/sqlite"
+
+	gl "github.com/AlexeyBelezeko/GitlabStatFetcher/pkg/gitlab"
+)
+
+// sqlSink persists commits/MRs/notes into SQLite or Postgres, auto-migrating
+// a devlake-style schema on first connect. Unlike the file-based sinks it
+// upserts by (project_id, id), so re-running a fetch merges new history
+// instead of appending duplicate rows.
+type sqlSink struct {
+	db *sql.DB
+}
+
+const sqlSchema = `
+CREATE TABLE IF NOT EXISTS commits (
+	project_id   BIGINT NOT NULL,
+	id           TEXT NOT NULL,
+	author_name  TEXT,
+	author_email TEXT,
+	date         TEXT,
+	message      TEXT,
+	additions    BIGINT,
+	deletions    BIGINT,
+	total        BIGINT,
+	PRIMARY KEY (project_id, id)
+);
+
+CREATE TABLE IF NOT EXISTS merge_requests (
+	project_id        BIGINT NOT NULL,
+	id                BIGINT NOT NULL,
+	title             TEXT,
+	state             TEXT,
+	author_username   TEXT,
+	author_name       TEXT,
+	created_at        TEXT,
+	merged_at         TEXT,
+	source_branch     TEXT,
+	target_branch     TEXT,
+	sha               TEXT,
+	merge_commit_sha  TEXT,
+	squash_commit_sha TEXT,
+	PRIMARY KEY (project_id, id)
+);
+
+CREATE TABLE IF NOT EXISTS notes (
+	project_id      BIGINT NOT NULL,
+	id              BIGINT NOT NULL,
+	author_name     TEXT,
+	author_username TEXT,
+	created_at      TEXT,
+	updated_at      TEXT,
+	body            TEXT,
+	system          BOOLEAN,
+	PRIMARY KEY (project_id, id)
+);
+
+CREATE TABLE IF NOT EXISTS pipelines (
+	project_id BIGINT NOT NULL,
+	id         BIGINT NOT NULL,
+	status     TEXT,
+	ref        TEXT,
+	sha        TEXT,
+	created_at TEXT,
+	updated_at TEXT,
+	PRIMARY KEY (project_id, id)
+);
+
+CREATE TABLE IF NOT EXISTS issues (
+	project_id      BIGINT NOT NULL,
+	id              BIGINT NOT NULL,
+	title           TEXT,
+	state           TEXT,
+	author_username TEXT,
+	author_name     TEXT,
+	created_at      TEXT,
+	updated_at      TEXT,
+	closed_at       TEXT,
+	PRIMARY KEY (project_id, id)
+);
+
+CREATE TABLE IF NOT EXISTS mr_approvals (
+	project_id          BIGINT NOT NULL,
+	mr_iid              BIGINT NOT NULL,
+	approvals_required  BIGINT,
+	approvals_left      BIGINT,
+	approved            BOOLEAN,
+	approved_by         TEXT,
+	PRIMARY KEY (project_id, mr_iid)
+);
+
+CREATE TABLE IF NOT EXISTS mr_commits (
+	project_id BIGINT NOT NULL,
+	mr_iid     BIGINT NOT NULL,
+	commit_id  TEXT NOT NULL,
+	PRIMARY KEY (project_id, mr_iid, commit_id)
+);
+`
+
+// newSQLSink opens dsn, which is either "sqlite://<path>" or a
+// "postgres(ql)://" connection string, and migrates the schema.
+func newSQLSink(dsn string) (*sqlSink, error) {
+	driver, dataSource := "sqlite", strings.TrimPrefix(dsn, "sqlite://")
+	if isPostgresDSN(dsn) {
+		driver, dataSource = "postgres", dsn
+	}
+
+	db, err := sql.Open(driver, dataSource)
+	if err != nil {
+		return nil, fmt.Errorf("opening %s database: %v", driver, err)
+	}
+	if err := db.Ping(); err != nil {
+		return nil, fmt.Errorf("connecting to %s database: %v", driver, err)
+	}
+	if _, err := db.Exec(sqlSchema); err != nil {
+		return nil, fmt.Errorf("migrating schema: %v", err)
+	}
+
+	return &sqlSink{db: db}, nil
+}
+
+func (s *sqlSink) WriteCommits(commits []*gl.Commit) error {
+	for _, c := range commits {
+		_, err := s.db.Exec(`
+			INSERT INTO commits (project_id, id, author_name, author_email, date, message, additions, deletions, total)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+			ON CONFLICT (project_id, id) DO UPDATE SET
+				author_name = excluded.author_name,
+				author_email = excluded.author_email,
+				date = excluded.date,
+				message = excluded.message,
+				additions = excluded.additions,
+				deletions = excluded.deletions,
+				total = excluded.total`,
+			c.ProjectID, c.ID, c.AuthorName, c.AuthorEmail, gl.FormatDate(c.CommittedDate), c.Message,
+			c.Stats.Additions, c.Stats.Deletions, c.Stats.Total)
+		if err != nil {
+			return fmt.Errorf("upserting commit %s: %v", c.ID, err)
+		}
+	}
+	return nil
+}
+
+func (s *sqlSink) WriteMRs(mrs []*gl.BasicMergeRequest) error {
+	for _, m := range mrs {
+		_, err := s.db.Exec(`
+			INSERT INTO merge_requests (project_id, id, title, state, author_username, author_name,
+				created_at, merged_at, source_branch, target_branch, sha, merge_commit_sha, squash_commit_sha)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+			ON CONFLICT (project_id, id) DO UPDATE SET
+				title = excluded.title,
+				state = excluded.state,
+				author_username = excluded.author_username,
+				author_name = excluded.author_name,
+				created_at = excluded.created_at,
+				merged_at = excluded.merged_at,
+				source_branch = excluded.source_branch,
+				target_branch = excluded.target_branch,
+				sha = excluded.sha,
+				merge_commit_sha = excluded.merge_commit_sha,
+				squash_commit_sha = excluded.squash_commit_sha`,
+			m.ProjectID, m.ID, m.Title, m.State, m.Author.Username, m.Author.Name,
+			gl.FormatDate(m.CreatedAt), gl.FormatDate(m.MergedAt), m.SourceBranch, m.TargetBranch,
+			m.SHA, m.MergeCommitSHA, m.SquashCommitSHA)
+		if err != nil {
+			return fmt.Errorf("upserting merge request %d: %v", m.ID, err)
+		}
+	}
+	return nil
+}
+
+func (s *sqlSink) WriteNotes(notes []*gl.Note) error {
+	for _, n := range notes {
+		_, err := s.db.Exec(`
+			INSERT INTO notes (project_id, id, author_name, author_username, created_at, updated_at, body, system)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			ON CONFLICT (project_id, id) DO UPDATE SET
+				author_name = excluded.author_name,
+				author_username = excluded.author_username,
+				created_at = excluded.created_at,
+				updated_at = excluded.updated_at,
+				body = excluded.body,
+				system = excluded.system`,
+			n.ProjectID, n.ID, n.Author.Name, n.Author.Username,
+			gl.FormatDate(n.CreatedAt), gl.FormatDate(n.UpdatedAt), n.Body, n.System)
+		if err != nil {
+			return fmt.Errorf("upserting note %d: %v", n.ID, err)
+		}
+	}
+	return nil
+}
+
+func (s *sqlSink) WritePipelines(pipelines []*gl.Pipeline) error {
+	for _, p := range pipelines {
+		_, err := s.db.Exec(`
+			INSERT INTO pipelines (project_id, id, status, ref, sha, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+			ON CONFLICT (project_id, id) DO UPDATE SET
+				status = excluded.status,
+				ref = excluded.ref,
+				sha = excluded.sha,
+				created_at = excluded.created_at,
+				updated_at = excluded.updated_at`,
+			p.ProjectID, p.ID, p.Status, p.Ref, p.SHA, gl.FormatDate(p.CreatedAt), gl.FormatDate(p.UpdatedAt))
+		if err != nil {
+			return fmt.Errorf("upserting pipeline %d: %v", p.ID, err)
+		}
+	}
+	return nil
+}
+
+func (s *sqlSink) WriteIssues(issues []*gl.Issue) error {
+	for _, i := range issues {
+		_, err := s.db.Exec(`
+			INSERT INTO issues (project_id, id, title, state, author_username, author_name, created_at, updated_at, closed_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+			ON CONFLICT (project_id, id) DO UPDATE SET
+				title = excluded.title,
+				state = excluded.state,
+				author_username = excluded.author_username,
+				author_name = excluded.author_name,
+				created_at = excluded.created_at,
+				updated_at = excluded.updated_at,
+				closed_at = excluded.closed_at`,
+			i.ProjectID, i.ID, i.Title, i.State, i.Author.Username, i.Author.Name,
+			gl.FormatDate(i.CreatedAt), gl.FormatDate(i.UpdatedAt), gl.FormatDate(i.ClosedAt))
+		if err != nil {
+			return fmt.Errorf("upserting issue %d: %v", i.ID, err)
+		}
+	}
+	return nil
+}
+
+func (s *sqlSink) WriteMRApprovals(approvals []*gl.MRApproval) error {
+	for _, a := range approvals {
+		_, err := s.db.Exec(`
+			INSERT INTO mr_approvals (project_id, mr_iid, approvals_required, approvals_left, approved, approved_by)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			ON CONFLICT (project_id, mr_iid) DO UPDATE SET
+				approvals_required = excluded.approvals_required,
+				approvals_left = excluded.approvals_left,
+				approved = excluded.approved,
+				approved_by = excluded.approved_by`,
+			a.ProjectID, a.MRIID, a.ApprovalsRequired, a.ApprovalsLeft, a.Approved, strings.Join(a.ApprovedBy, ";"))
+		if err != nil {
+			return fmt.Errorf("upserting merge request approval %d: %v", a.MRIID, err)
+		}
+	}
+	return nil
+}
+
+func (s *sqlSink) WriteMRCommits(mrCommits []*gl.MRCommit) error {
+	for _, c := range mrCommits {
+		_, err := s.db.Exec(`
+			INSERT INTO mr_commits (project_id, mr_iid, commit_id)
+			VALUES ($1, $2, $3)
+			ON CONFLICT (project_id, mr_iid, commit_id) DO NOTHING`,
+			c.ProjectID, c.MRIID, c.CommitID)
+		if err != nil {
+			return fmt.Errorf("upserting merge request commit %s: %v", c.CommitID, err)
+		}
+	}
+	return nil
+}
+
+func (s *sqlSink) Flush() error { return nil }
+
+func (s *sqlSink) Close() error { return s.db.Close() }
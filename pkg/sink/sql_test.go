@@ -0,0 +1,87 @@
+package sink
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	gl "github.com/AlexeyBelezeko/GitlabStatFetcher/pkg/gitlab"
+)
+
+func TestSQLSinkWriteCommitsUpsertsOnRerun(t *testing.T) {
+	dsn := "sqlite://" + filepath.Join(t.TempDir(), "gitlab.db")
+	s, err := newSQLSink(dsn)
+	if err != nil {
+		t.Fatalf("newSQLSink: %v", err)
+	}
+	defer s.Close()
+
+	commit := &gl.Commit{ProjectID: 1, ID: "abc123", AuthorName: "Ada", Message: "first pass", Stats: &gl.CommitStats{}}
+	if err := s.WriteCommits([]*gl.Commit{commit}); err != nil {
+		t.Fatalf("WriteCommits (initial): %v", err)
+	}
+
+	// Re-fetching the same project/commit (e.g. a later "resume" or
+	// re-run) should update the existing row, not insert a duplicate.
+	commit.AuthorName = "Ada Lovelace"
+	commit.Message = "amended message"
+	if err := s.WriteCommits([]*gl.Commit{commit}); err != nil {
+		t.Fatalf("WriteCommits (rerun): %v", err)
+	}
+
+	var count int
+	var authorName, message string
+	row := s.db.QueryRow(`SELECT COUNT(*), author_name, message FROM commits WHERE project_id = ? AND id = ? GROUP BY author_name, message`, 1, "abc123")
+	if err := row.Scan(&count, &authorName, &message); err != nil {
+		t.Fatalf("querying commits: %v", err)
+	}
+
+	if count != 1 {
+		t.Fatalf("row count = %d, want 1 (upsert should replace, not duplicate)", count)
+	}
+	if authorName != "Ada Lovelace" || message != "amended message" {
+		t.Fatalf("row = (%q, %q), want the rerun's updated values", authorName, message)
+	}
+}
+
+func TestSQLSinkWriteMRCommitsIgnoresDuplicates(t *testing.T) {
+	dsn := "sqlite://" + filepath.Join(t.TempDir(), "gitlab.db")
+	s, err := newSQLSink(dsn)
+	if err != nil {
+		t.Fatalf("newSQLSink: %v", err)
+	}
+	defer s.Close()
+
+	mrCommit := &gl.MRCommit{ProjectID: 1, MRIID: 2, CommitID: "abc123"}
+	if err := s.WriteMRCommits([]*gl.MRCommit{mrCommit}); err != nil {
+		t.Fatalf("WriteMRCommits (initial): %v", err)
+	}
+	if err := s.WriteMRCommits([]*gl.MRCommit{mrCommit}); err != nil {
+		t.Fatalf("WriteMRCommits (rerun): %v", err)
+	}
+
+	var count int
+	if err := s.db.QueryRow(`SELECT COUNT(*) FROM mr_commits WHERE project_id = ? AND mr_iid = ? AND commit_id = ?`, 1, 2, "abc123").Scan(&count); err != nil {
+		t.Fatalf("querying mr_commits: %v", err)
+	}
+	if count != 1 {
+		t.Fatalf("row count = %d, want 1 (ON CONFLICT DO NOTHING should dedupe)", count)
+	}
+}
+
+func TestNewSQLSinkMigratesSchema(t *testing.T) {
+	dsn := "sqlite://" + filepath.Join(t.TempDir(), "gitlab.db")
+	s, err := newSQLSink(dsn)
+	if err != nil {
+		t.Fatalf("newSQLSink: %v", err)
+	}
+	defer s.Close()
+
+	var name string
+	if err := s.db.QueryRow(`SELECT name FROM sqlite_master WHERE type = 'table' AND name = 'commits'`).Scan(&name); err != nil {
+		if err == sql.ErrNoRows {
+			t.Fatalf("commits table was not created by schema migration")
+		}
+		t.Fatalf("querying sqlite_master: %v", err)
+	}
+}